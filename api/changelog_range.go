@@ -0,0 +1,54 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// ChangelogRange consolidates the changelogs of the product releases after
+// From up to and including To.
+type ChangelogRange struct {
+	ProductLine string         `json:"product_line"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Releases    []string       `json:"releases"`
+	Projects    []ProjectRange `json:"projects"`
+}
+
+// ProjectRange is the version jump of a project between two product releases.
+type ProjectRange struct {
+	URL string `json:"url"`
+	// From is the tag shipped in the From release or earlier. It falls back
+	// to the changelog base of the first tag in the range and is empty for
+	// projects that are new in the range.
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	// Breaking is set if any commit is a breaking change or requires action.
+	Breaking bool     `json:"breaking,omitempty"`
+	Commits  []Commit `json:"commits"`
+}
+
+func (p ProjectRange) Sections() []CommitSection {
+	return ReleaseChangelog{Tag: p.To, Base: p.From, Commits: p.Commits}.Sections()
+}
+
+func (r ChangelogRange) BreakingProjects() []ProjectRange {
+	var out []ProjectRange
+	for _, p := range r.Projects {
+		if p.Breaking {
+			out = append(out, p)
+		}
+	}
+	return out
+}
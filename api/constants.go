@@ -24,6 +24,7 @@ const (
 	LabelLocked    = "locked"
 	LabelAutoMerge = "automerge"
 	ReleasesDir    = "releases"
+	TemplatesDir   = ".release-automaton/templates"
 
 	StableChartRegistry     = "appscode"
 	StableChartRegistryURL  = "https://charts.appscode.com/stable/"
@@ -38,3 +39,14 @@ const (
 	TestBundleRegistry      = "bytebuilders-testing"
 	TestBundleRegistryURL   = "https://bundles.byte.builders/testing/"
 )
+
+// GitSigningKeyEnv holds the private key used to sign release commits and tags.
+const GitSigningKeyEnv = "GIT_SIGNING_KEY"
+
+// GitHub App credentials. If GITHUB_APP_ID is set, installation tokens of the
+// app are used instead of GITHUB_TOKEN.
+const (
+	GitHubAppIDKey             = "GITHUB_APP_ID"
+	GitHubAppPrivateKeyKey     = "GITHUB_APP_PRIVATE_KEY"
+	GitHubAppPrivateKeyFileKey = "GITHUB_APP_PRIVATE_KEY_FILE"
+)
@@ -0,0 +1,134 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"sort"
+	"strings"
+)
+
+type Contributor struct {
+	// Name is the GitHub login if known, otherwise the commit author name.
+	Name    string
+	Login   bool
+	Commits int
+	// FirstTime is set for authors without commits in earlier releases.
+	FirstTime bool
+}
+
+type RepoContributors struct {
+	Repo         string
+	Contributors int
+	Commits      int
+}
+
+func contributorName(c Commit) (string, bool) {
+	if c.AuthorLogin != "" {
+		return c.AuthorLogin, true
+	}
+	return c.Author, false
+}
+
+func (chlog Changelog) isBot(name string) bool {
+	if strings.HasSuffix(name, "[bot]") {
+		return true
+	}
+	for _, bot := range chlog.Bots {
+		if strings.EqualFold(bot, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithContributors returns a copy of the changelog that credits the authors
+// of its commits, most active first. Authors without commits in any of the
+// earlier changelogs are marked as first-time contributors. Changelogs written
+// before commit authors were recorded can't tell who is new, so nobody is
+// marked unless an earlier changelog has author data.
+func (chlog Changelog) WithContributors(earlier []Changelog) Changelog {
+	known := map[string]bool{}
+	for _, e := range earlier {
+		for _, p := range e.Projects {
+			for _, r := range p.Releases {
+				for _, c := range r.Commits {
+					if name, _ := contributorName(c); name != "" {
+						known[strings.ToLower(name)] = true
+					}
+					if c.Author != "" {
+						known[strings.ToLower(c.Author)] = true
+					}
+				}
+			}
+		}
+	}
+
+	out := chlog
+	out.Contributors = nil
+	out.RepoContributors = nil
+	contributors := map[string]*Contributor{}
+	for _, p := range chlog.Projects {
+		repo := RepoContributors{Repo: p.URL}
+		names := map[string]bool{}
+		for _, r := range p.Releases {
+			for _, c := range r.Commits {
+				name, login := contributorName(c)
+				if name == "" || chlog.isBot(name) || chlog.isBot(c.Author) {
+					continue
+				}
+				key := strings.ToLower(name)
+				ct, ok := contributors[key]
+				if !ok {
+					ct = &Contributor{
+						Name:      name,
+						Login:     login,
+						FirstTime: len(known) > 0 && !known[key] && !known[strings.ToLower(c.Author)],
+					}
+					contributors[key] = ct
+				}
+				ct.Commits++
+				repo.Commits++
+				names[key] = true
+			}
+		}
+		if repo.Commits > 0 {
+			repo.Contributors = len(names)
+			out.RepoContributors = append(out.RepoContributors, repo)
+		}
+	}
+
+	for _, ct := range contributors {
+		out.Contributors = append(out.Contributors, *ct)
+	}
+	sort.Slice(out.Contributors, func(i, j int) bool {
+		if out.Contributors[i].Commits != out.Contributors[j].Commits {
+			return out.Contributors[i].Commits > out.Contributors[j].Commits
+		}
+		return strings.ToLower(out.Contributors[i].Name) < strings.ToLower(out.Contributors[j].Name)
+	})
+	return out
+}
+
+func (chlog Changelog) FirstTimeContributors() []Contributor {
+	var out []Contributor
+	for _, ct := range chlog.Contributors {
+		if ct.FirstTime {
+			out = append(out, ct)
+		}
+	}
+	return out
+}
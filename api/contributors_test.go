@@ -0,0 +1,103 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithContributors(t *testing.T) {
+	earlier := []Changelog{
+		{
+			Release: "v2026.1.19",
+			Projects: []ProjectChangelog{
+				{URL: "github.com/kubedb/operator", Releases: []ReleaseChangelog{{Tag: "v0.39.0", Commits: []Commit{
+					{SHA: "a0", Author: "Alice", AuthorLogin: "alice"},
+				}}}},
+			},
+		},
+	}
+	chlog := Changelog{
+		Release: "v2026.7.10",
+		Bots:    []string{"1gtm"},
+		Projects: []ProjectChangelog{
+			{URL: "github.com/kubedb/operator", Releases: []ReleaseChangelog{{Tag: "v0.40.0", Commits: []Commit{
+				{SHA: "a1", Author: "Alice", AuthorLogin: "alice"},
+				{SHA: "a2", Author: "Alice", AuthorLogin: "alice"},
+				{SHA: "b1", Author: "Bob"},
+				{SHA: "d1", Author: "dependabot[bot]", AuthorLogin: "dependabot[bot]"},
+				{SHA: "g1", Author: "1gtm"},
+			}}}},
+			{URL: "github.com/kubedb/cli", Releases: []ReleaseChangelog{{Tag: "v0.40.0", Commits: []Commit{
+				{SHA: "c1", Author: "Carol", AuthorLogin: "carol"},
+			}}}},
+		},
+	}
+
+	out := chlog.WithContributors(earlier)
+	wantContributors := []Contributor{
+		{Name: "alice", Login: true, Commits: 2},
+		{Name: "Bob", Commits: 1, FirstTime: true},
+		{Name: "carol", Login: true, Commits: 1, FirstTime: true},
+	}
+	if !reflect.DeepEqual(out.Contributors, wantContributors) {
+		t.Errorf("Contributors = %+v, want %+v", out.Contributors, wantContributors)
+	}
+	wantRepos := []RepoContributors{
+		{Repo: "github.com/kubedb/operator", Contributors: 2, Commits: 3},
+		{Repo: "github.com/kubedb/cli", Contributors: 1, Commits: 1},
+	}
+	if !reflect.DeepEqual(out.RepoContributors, wantRepos) {
+		t.Errorf("RepoContributors = %+v, want %+v", out.RepoContributors, wantRepos)
+	}
+	if got := len(out.FirstTimeContributors()); got != 2 {
+		t.Errorf("FirstTimeContributors() has %d contributors, want 2", got)
+	}
+}
+
+func TestWithContributorsWithoutAuthorData(t *testing.T) {
+	earlier := []Changelog{
+		{
+			Release: "v2025.12.9",
+			Projects: []ProjectChangelog{
+				{URL: "github.com/kubedb/operator", Releases: []ReleaseChangelog{{Tag: "v0.38.0", Commits: []Commit{
+					{SHA: "a0", Subject: "Prepare for release v0.38.0"},
+				}}}},
+			},
+		},
+	}
+	chlog := Changelog{
+		Release: "v2026.1.19",
+		Projects: []ProjectChangelog{
+			{URL: "github.com/kubedb/operator", Releases: []ReleaseChangelog{{Tag: "v0.39.0", Commits: []Commit{
+				{SHA: "a1", Author: "Alice", AuthorLogin: "alice"},
+			}}}},
+		},
+	}
+
+	out := chlog.WithContributors(earlier)
+	want := []Contributor{
+		{Name: "alice", Login: true, Commits: 1},
+	}
+	if !reflect.DeepEqual(out.Contributors, want) {
+		t.Errorf("Contributors = %+v, want %+v", out.Contributors, want)
+	}
+	if got := len(out.FirstTimeContributors()); got != 0 {
+		t.Errorf("FirstTimeContributors() has %d contributors, want 0", got)
+	}
+}
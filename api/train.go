@@ -0,0 +1,90 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ReleaseTrain sequences the releases of several products. A product release
+// file can reference the resolved versions of a product listed before it as
+// ${<product>.<VAR>}, eg, ${kubedb.RELEASE} or ${kubedb.KUBEDB_CLI_TAG}.
+type ReleaseTrain struct {
+	Name string `json:"name"`
+	// Tracker is the release tracker pull request shared by all products.
+	// Products with their own tracker ignore it.
+	Tracker  string         `json:"tracker,omitempty"`
+	Products []TrainProduct `json:"products"`
+}
+
+type TrainProduct struct {
+	// Name is used to reference this product from later products.
+	Name        string `json:"name"`
+	ReleaseFile string `json:"release_file"`
+	Tracker     string `json:"tracker,omitempty"`
+}
+
+var trainProductName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// TrainRefRegex matches a ${<product>.<VAR>} reference to an earlier product.
+var TrainRefRegex = regexp.MustCompile(`\$\{([a-z0-9][a-z0-9-]*)\.([A-Za-z0-9_]+)\}`)
+
+func (t ReleaseTrain) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("missing train name")
+	}
+	if len(t.Products) == 0 {
+		return fmt.Errorf("train %s has no products", t.Name)
+	}
+	names := map[string]bool{}
+	for _, p := range t.Products {
+		if !trainProductName.MatchString(p.Name) {
+			return fmt.Errorf("train %s has invalid product name %q", t.Name, p.Name)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("train %s lists product %s more than once", t.Name, p.Name)
+		}
+		names[p.Name] = true
+		if p.ReleaseFile == "" {
+			return fmt.Errorf("train %s is missing release file for product %s", t.Name, p.Name)
+		}
+		if t.TrackerOf(p) == "" {
+			return fmt.Errorf("train %s is missing release tracker for product %s", t.Name, p.Name)
+		}
+	}
+	return nil
+}
+
+func (t ReleaseTrain) TrackerOf(p TrainProduct) string {
+	if p.Tracker != "" {
+		return p.Tracker
+	}
+	return t.Tracker
+}
+
+// SharesTracker returns true if the tracker of p is used by other products
+// too. In that case the /done reply must name the product.
+func (t ReleaseTrain) SharesTracker(p TrainProduct) bool {
+	tracker := t.TrackerOf(p)
+	for _, other := range t.Products {
+		if other.Name != p.Name && t.TrackerOf(other) == tracker {
+			return true
+		}
+	}
+	return false
+}
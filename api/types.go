@@ -18,6 +18,7 @@ package api
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -30,16 +31,38 @@ import (
 
 type ProjectMeta interface {
 	GetCommands() []string
+	GetSteps() []Step
+}
+
+// Step is a structured command. Steps run after the plain commands of a
+// project.
+type Step struct {
+	Run string `json:"run"`
+	// Dir is relative to the project workspace.
+	Dir string            `json:"dir,omitempty"`
+	Env map[string]string `json:"env,omitempty"`
+	// When is a condition over release, tag, prerelease, public, channel and
+	// hideDocs, eg, "!hideDocs && public". The step is skipped if false.
+	When            string `json:"when,omitempty"`
+	ContinueOnError bool   `json:"continueOnError,omitempty"`
+	// Timeout is a duration, eg, 10m.
+	Timeout string `json:"timeout,omitempty"`
+	Retries int    `json:"retries,omitempty"`
 }
 
 type ExternalProject struct {
 	Commands []string `json:"commands,omitempty"`
+	Steps    []Step   `json:"steps,omitempty"`
 }
 
 func (p ExternalProject) GetCommands() []string {
 	return p.Commands
 }
 
+func (p ExternalProject) GetSteps() []Step {
+	return p.Steps
+}
+
 type Project struct {
 	Key           string            `json:"key,omitempty"`
 	Tag           *string           `json:"tag,omitempty"`
@@ -47,16 +70,25 @@ type Project struct {
 	ChartNames    []string          `json:"chartNames,omitempty"`
 	ChartRepos    []string          `json:"charts,omitempty"`
 	Commands      []string          `json:"commands,omitempty"`
+	Steps         []Step            `json:"steps,omitempty"`
 	ReleaseBranch string            `json:"release_branch,omitempty"`
 	ReadyToTag    bool              `json:"ready_to_tag,omitempty"`
 	Changelog     ChangelogStatus   `json:"changelog,omitempty"`
-	SubProjects   []string          `json:"sub_projects,omitempty"`
+	// SameReleaseLine restricts the changelog base of a tag to the previous
+	// tag with the same major and minor version, eg, v0.9.3 for v0.9.4.
+	SameReleaseLine  bool             `json:"same_release_line,omitempty"`
+	APICompatibility APICompatibility `json:"api_compatibility,omitempty"`
+	SubProjects      []string         `json:"sub_projects,omitempty"`
 }
 
 func (p Project) GetCommands() []string {
 	return p.Commands
 }
 
+func (p Project) GetSteps() []Step {
+	return p.Steps
+}
+
 type ChangelogStatus string
 
 const (
@@ -66,6 +98,16 @@ const (
 	SharedWebsiteChangelog     ChangelogStatus = "SharedWebsite"
 )
 
+// APICompatibility decides what happens to incompatible API changes of the
+// Go modules of a project, as reported by gorelease.
+type APICompatibility string
+
+const (
+	ReportAPICompatibility  APICompatibility = "" // by default listed in changelog
+	EnforceAPICompatibility APICompatibility = "Enforce"
+	SkipAPICompatibility    APICompatibility = "Skip"
+)
+
 type IndependentProjects map[string]Project
 
 type Release struct {
@@ -73,11 +115,242 @@ type Release struct {
 	Release           string `json:"release"`
 	DocsURLTemplate   string `json:"docs_url_template"` // "https://stash.run/docs/%s"
 	KubernetesVersion string `json:"kubernetes_version"`
+	// ReleaseDate overrides the release date of the changelog, eg, 2026-07-10.
+	// By default, it is the time of the /done reply.
+	ReleaseDate string `json:"release_date,omitempty"`
+	// HideDocs hides the docs of this release from the website.
+	HideDocs         bool              `json:"hide_docs,omitempty"`
+	ChangelogFilters *ChangelogFilters `json:"changelog_filters,omitempty"`
+	// Signing signs the release commits and tags of the product.
+	Signing *SigningConfig `json:"signing,omitempty"`
+	// Base is the release this release is derived from, eg, v2026.7.10.
+	// A release file with a base only lists its Overrides and is resolved
+	// against releases/<base>/release.json.
+	Base      string            `json:"base,omitempty"`
+	Overrides *ReleaseOverrides `json:"overrides,omitempty"`
 	// These projects can be released in sequence
 	Projects         []IndependentProjects      `json:"projects"`
 	ExternalProjects map[string]ExternalProject `json:"external_projects,omitempty"`
 }
 
+// ChangelogFilters hide noise from changelogs. Matching commits are kept in
+// CHANGELOG.json with filtered: true.
+type ChangelogFilters struct {
+	// Subjects are regular expressions matched against commit subjects.
+	Subjects []string `json:"subjects,omitempty"`
+	// Authors are author names or GitHub logins, eg, dependabot[bot].
+	Authors []string `json:"authors,omitempty"`
+	// Labels are pull request labels, eg, skip-changelog.
+	Labels []string `json:"labels,omitempty"`
+	// CollapseThreshold lists subjects found in at least this many repos
+	// once for the product. Values below 2 disable collapsing.
+	CollapseThreshold int `json:"collapse_threshold,omitempty"`
+	// Bots are GitHub logins or author names left out of the contributors.
+	// Logins ending in [bot] are always left out.
+	Bots []string `json:"bots,omitempty"`
+}
+
+func (f ChangelogFilters) Validate() error {
+	for _, expr := range f.Subjects {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid changelog subject filter %q: %v", expr, err)
+		}
+	}
+	return nil
+}
+
+type SigningFormat string
+
+const (
+	SigningFormatOpenPGP SigningFormat = "openpgp"
+	SigningFormatSSH     SigningFormat = "ssh"
+)
+
+// SigningConfig configures the key used to sign commits and tags. The
+// private key is read from the KeyEnv environment variable, or KeyFile.
+type SigningConfig struct {
+	// Format is openpgp (default) or ssh.
+	Format SigningFormat `json:"format,omitempty"`
+	// KeyEnv is the environment variable holding the private key.
+	// Defaults to GIT_SIGNING_KEY.
+	KeyEnv  string `json:"key_env,omitempty"`
+	KeyFile string `json:"key_file,omitempty"`
+	// AllowedSignersFile lists the keys release tags must be signed by,
+	// relative to the release tracker repo. For openpgp, it holds armored
+	// public keys. For ssh, it uses the allowed signers format of ssh-keygen.
+	AllowedSignersFile string `json:"allowed_signers_file,omitempty"`
+}
+
+func (c SigningConfig) GetFormat() SigningFormat {
+	if c.Format == "" {
+		return SigningFormatOpenPGP
+	}
+	return c.Format
+}
+
+func (c SigningConfig) GetKeyEnv() string {
+	if c.KeyEnv == "" {
+		return GitSigningKeyEnv
+	}
+	return c.KeyEnv
+}
+
+func (c SigningConfig) Validate() error {
+	switch c.GetFormat() {
+	case SigningFormatOpenPGP, SigningFormatSSH:
+		return nil
+	default:
+		return fmt.Errorf("unknown signing format %s", c.Format)
+	}
+}
+
+type ReleaseOverrides struct {
+	// Tags changes the tag of existing projects, keyed by repo url.
+	Tags map[string]string `json:"tags,omitempty"`
+	// Commands replaces the commands of existing projects, keyed by repo url.
+	Commands map[string][]string `json:"commands,omitempty"`
+	// Steps replaces the steps of existing projects, keyed by repo url.
+	Steps map[string][]Step `json:"steps,omitempty"`
+	// Add adds projects to a group of the base release. Group len(projects)
+	// appends a new group at the end.
+	Add []ProjectGroupOverride `json:"add,omitempty"`
+	// Remove removes projects by repo url.
+	Remove []string `json:"remove,omitempty"`
+}
+
+type ProjectGroupOverride struct {
+	Group    int                 `json:"group"`
+	Projects IndependentProjects `json:"projects"`
+}
+
+// Resolve applies the overrides of r on top of its base release and returns
+// the flattened release. Projects tagged with the base release number are
+// moved to the new release number.
+func (r Release) Resolve(base Release) (Release, error) {
+	if r.Base != base.Release {
+		return Release{}, fmt.Errorf("release %s expects base %s, found %s", r.Release, r.Base, base.Release)
+	}
+	if len(r.Projects) > 0 {
+		return Release{}, fmt.Errorf("release %s with base %s must not list projects, use overrides instead", r.Release, r.Base)
+	}
+	if r.ProductLine != "" && r.ProductLine != base.ProductLine {
+		return Release{}, fmt.Errorf("release %s uses product line %s but base %s uses %s", r.Release, r.ProductLine, base.Release, base.ProductLine)
+	}
+
+	out := Release{
+		ProductLine:       base.ProductLine,
+		Release:           r.Release,
+		DocsURLTemplate:   base.DocsURLTemplate,
+		KubernetesVersion: base.KubernetesVersion,
+		ReleaseDate:       r.ReleaseDate,
+		HideDocs:          r.HideDocs || base.HideDocs,
+		ChangelogFilters:  base.ChangelogFilters,
+		Signing:           base.Signing,
+		Projects:          make([]IndependentProjects, 0, len(base.Projects)),
+		ExternalProjects:  base.ExternalProjects,
+	}
+	if r.DocsURLTemplate != "" {
+		out.DocsURLTemplate = r.DocsURLTemplate
+	}
+	if r.KubernetesVersion != "" {
+		out.KubernetesVersion = r.KubernetesVersion
+	}
+	if r.ExternalProjects != nil {
+		out.ExternalProjects = r.ExternalProjects
+	}
+	if r.Signing != nil {
+		out.Signing = r.Signing
+	}
+	if r.ChangelogFilters != nil {
+		out.ChangelogFilters = r.ChangelogFilters
+	}
+
+	for _, projects := range base.Projects {
+		group := make(IndependentProjects, len(projects))
+		for repoURL, project := range projects {
+			if project.Tag != nil && *project.Tag == base.Release {
+				project.Tag = &out.Release
+			}
+			group[repoURL] = project
+		}
+		out.Projects = append(out.Projects, group)
+	}
+
+	if o := r.Overrides; o != nil {
+		for _, repoURL := range o.Remove {
+			if _, ok := out.findProject(repoURL); !ok {
+				return Release{}, fmt.Errorf("can't remove repo %s, not found in base release %s", repoURL, base.Release)
+			}
+			for _, projects := range out.Projects {
+				delete(projects, repoURL)
+			}
+		}
+		for repoURL, tag := range o.Tags {
+			idx, ok := out.findProject(repoURL)
+			if !ok {
+				return Release{}, fmt.Errorf("can't change tag of repo %s, not found in base release %s", repoURL, base.Release)
+			}
+			project := out.Projects[idx][repoURL]
+			if project.Tags != nil {
+				return Release{}, fmt.Errorf("can't change tag of repo %s which uses tags, remove and add it instead", repoURL)
+			}
+			project.Tag = &tag
+			out.Projects[idx][repoURL] = project
+		}
+		for repoURL, commands := range o.Commands {
+			idx, ok := out.findProject(repoURL)
+			if !ok {
+				return Release{}, fmt.Errorf("can't change commands of repo %s, not found in base release %s", repoURL, base.Release)
+			}
+			project := out.Projects[idx][repoURL]
+			project.Commands = commands
+			out.Projects[idx][repoURL] = project
+		}
+		for repoURL, steps := range o.Steps {
+			idx, ok := out.findProject(repoURL)
+			if !ok {
+				return Release{}, fmt.Errorf("can't change steps of repo %s, not found in base release %s", repoURL, base.Release)
+			}
+			project := out.Projects[idx][repoURL]
+			project.Steps = steps
+			out.Projects[idx][repoURL] = project
+		}
+		for _, add := range o.Add {
+			if add.Group < 0 || add.Group > len(out.Projects) {
+				return Release{}, fmt.Errorf("can't add projects to group %d, base release %s has %d groups", add.Group, base.Release, len(base.Projects))
+			}
+			if add.Group == len(out.Projects) {
+				out.Projects = append(out.Projects, IndependentProjects{})
+			}
+			for repoURL, project := range add.Projects {
+				if _, ok := out.findProject(repoURL); ok {
+					return Release{}, fmt.Errorf("can't add repo %s, already exists in base release %s", repoURL, base.Release)
+				}
+				out.Projects[add.Group][repoURL] = project
+			}
+		}
+	}
+
+	groups := out.Projects[:0]
+	for _, projects := range out.Projects {
+		if len(projects) > 0 {
+			groups = append(groups, projects)
+		}
+	}
+	out.Projects = groups
+
+	return out, out.Validate()
+}
+
+func (r Release) findProject(repoURL string) (int, bool) {
+	for idx, projects := range r.Projects {
+		if _, ok := projects[repoURL]; ok {
+			return idx, true
+		}
+	}
+	return -1, false
+}
+
 func (r Release) Validate() error {
 	if r.Release == "" {
 		return fmt.Errorf("missing release number")
@@ -86,10 +359,21 @@ func (r Release) Validate() error {
 	if err != nil {
 		return err
 	}
+	if r.Signing != nil {
+		if err := r.Signing.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.ChangelogFilters != nil {
+		if err := r.ChangelogFilters.Validate(); err != nil {
+			return err
+		}
+	}
 	for _, projects := range r.Projects {
 		for repoURL, project := range projects {
-			// only check projects that uses semver tags (ie, does not match release number)
-			if project.Tag != nil && r.Release != *project.Tag {
+			// only check projects that uses semver tags (ie, does not match release number).
+			// ${<product>.<VAR>} references of a train are checked once they are resolved.
+			if project.Tag != nil && r.Release != *project.Tag && !TrainRefRegex.MatchString(*project.Tag) {
 				projectVersion, err := StrictParseVersion(*project.Tag)
 				if err != nil {
 					return fmt.Errorf("invalid tag for repo %s: %s", repoURL, err)
@@ -110,6 +394,20 @@ func (r Release) Validate() error {
 	return nil
 }
 
+const (
+	ChannelStable  = "stable"
+	ChannelTesting = "testing"
+)
+
+// Channel returns testing for alpha and beta releases and stable otherwise.
+func (r Release) Channel() string {
+	v, err := semver.NewVersion(r.Release)
+	if err == nil && (strings.HasPrefix(v.Prerelease(), "alpha.") || strings.HasPrefix(v.Prerelease(), "beta.")) {
+		return ChannelTesting
+	}
+	return ChannelStable
+}
+
 // StrictParseVersion behaves as semver.StrictNewVersion, with as sole exception
 // that it allows versions with a preceding "v" (i.e. v1.2.3).
 // Ensure new releases are FluxCD compatible.
@@ -138,14 +436,21 @@ const (
 	ReadyToTag   ReplyType = "/ready-to-tag"
 	CherryPicked ReplyType = "/cherry-picked"
 	PR           ReplyType = "/pr"
+	// PRClosed flags a release pr closed without merging.
+	PRClosed ReplyType = "/pr-closed"
 
 	Chart          ReplyType = "/chart"
 	ChartPublished ReplyType = "/chart-published"
 
 	KrewManifest          ReplyType = "/krew-manifest"
 	KrewManifestPublished ReplyType = "/krew-manifest-published"
+
+	Note ReplyType = "/note"
 )
 
+// NoteAllRepos is the repo of a /note for the whole product.
+const NoteAllRepos = "*"
+
 type Replies map[ReplyType][]Reply
 
 func MergeReplies(replies Replies, elems ...Reply) Replies {
@@ -196,8 +501,10 @@ func AppendReplyIfMissing(replies Replies, r Reply) (Replies, bool) {
 
 type Reply struct {
 	Type                  ReplyType
+	Done                  *DoneReplyData
 	Tagged                *TaggedReplyData
 	PR                    *PullRequestReplyData
+	PRClosed              *PullRequestReplyData
 	ReadyToTag            *ReadyToTagReplyData
 	CherryPicked          *CherryPickedReplyData
 	Go                    *GoReplyData
@@ -205,6 +512,7 @@ type Reply struct {
 	ChartPublished        *ChartPublishedReplyData
 	KrewManifest          *KrewManifestReplyData
 	KrewManifestPublished *KrewManifestPublishedReplyData
+	Note                  *NoteReplyData
 }
 
 type ReplyKey struct {
@@ -215,13 +523,18 @@ type ReplyKey struct {
 func (r Reply) Key() ReplyKey {
 	switch r.Type {
 	case OkToRelease:
-		fallthrough
+		return ReplyKey{}
 	case Done:
+		if r.Done != nil {
+			return ReplyKey{B: r.Done.Product}
+		}
 		return ReplyKey{}
 	case Tagged:
 		return ReplyKey{Repo: r.Tagged.Repo}
 	case PR:
 		return ReplyKey{Repo: r.PR.Repo, B: strconv.Itoa(r.PR.Number)}
+	case PRClosed:
+		return ReplyKey{Repo: r.PRClosed.Repo, B: strconv.Itoa(r.PRClosed.Number)}
 	case Go:
 		return ReplyKey{Repo: r.Go.Repo}
 	case ReadyToTag:
@@ -236,11 +549,37 @@ func (r Reply) Key() ReplyKey {
 		return ReplyKey{Repo: r.KrewManifest.Repo}
 	case KrewManifestPublished:
 		return ReplyKey{Repo: r.KrewManifestPublished.Repo}
+	case Note:
+		return ReplyKey{Repo: r.Note.Repo, B: r.Note.Text}
 	default:
 		panic(fmt.Errorf("unknown reply type %s", r.Type))
 	}
 }
 
+// DoneReplyData names the product of a release train that is done, when
+// several products share the same release tracker.
+type DoneReplyData struct {
+	Product string
+}
+
+// IsDone checks for a /done reply for product. An empty product matches a
+// plain /done reply.
+func (replies Replies) IsDone(product string) bool {
+	for _, r := range replies[Done] {
+		if r.Key().B == product {
+			return true
+		}
+	}
+	return false
+}
+
+// NoteReplyData is a note of the release captain about a repo, eg, known
+// issues or upgrade instructions. Repo is * for notes about the product.
+type NoteReplyData struct {
+	Repo string
+	Text string
+}
+
 type TaggedReplyData struct {
 	Repo string
 }
@@ -294,18 +633,122 @@ type KrewManifestPublishedReplyData struct {
 	Repo string
 }
 
+type CommitCategory string
+
+const (
+	CategoryBreaking     CommitCategory = "Breaking Changes"
+	CategoryFeature      CommitCategory = "Features"
+	CategoryBugFix       CommitCategory = "Bug Fixes"
+	CategoryDependencies CommitCategory = "Dependencies"
+	CategoryChore        CommitCategory = "Chores"
+	CategoryOther        CommitCategory = "Other"
+)
+
+// CommitCategories lists the categories in the order they are rendered.
+var CommitCategories = []CommitCategory{
+	CategoryBreaking,
+	CategoryFeature,
+	CategoryBugFix,
+	CategoryDependencies,
+	CategoryChore,
+	CategoryOther,
+}
+
 type Commit struct {
-	SHA     string
-	Subject string
+	SHA         string
+	Subject     string
+	Category    CommitCategory `json:"Category,omitempty"`
+	Author      string         `json:"Author,omitempty"`
+	AuthorLogin string         `json:"AuthorLogin,omitempty"`
+	Date        time.Time      `json:"Date,omitzero"`
+	PR          int            `json:"PR,omitempty"`
+	PRURL       string         `json:"PRURL,omitempty"`
+	// ReleaseNote is harvested from release-note blocks of the pull request
+	// or Release-note: trailers of the commit.
+	ReleaseNote    string   `json:"ReleaseNote,omitempty"`
+	ActionRequired bool     `json:"ActionRequired,omitempty"`
+	Labels         []string `json:"Labels,omitempty"`
+	// Security fixes are labelled security or mention CVE or GHSA ids,
+	// which are listed in Advisories.
+	Security   bool     `json:"Security,omitempty"`
+	Advisories []string `json:"Advisories,omitempty"`
+	// Filtered commits are kept in CHANGELOG.json but not rendered.
+	Filtered bool `json:"filtered,omitempty"`
 }
 
 type ReleaseChangelog struct {
-	Tag     string   `json:"tag"`
+	Tag string `json:"tag"`
+	// Base is the tag the commits are listed from. It is empty when the
+	// commits start at the first commit of the repo.
+	Base    string   `json:"base,omitempty"`
 	Commits []Commit `json:"commits"`
+	// APIChanges lists the Go modules with incompatible API changes since Base.
+	APIChanges []ModuleAPIChanges `json:"api_changes,omitempty"`
+}
+
+type ModuleAPIChanges struct {
+	Module       string   `json:"module"`
+	Incompatible []string `json:"incompatible"`
+}
+
+type CommitSection struct {
+	Category CommitCategory
+	Commits  []Commit
+}
+
+// Sections groups the commits by category. Changelogs recorded before
+// commits were categorised return a single section without a category.
+// Filtered commits are skipped.
+func (r ReleaseChangelog) Sections() []CommitSection {
+	visible := make([]Commit, 0, len(r.Commits))
+	for _, c := range r.Commits {
+		if !c.Filtered {
+			visible = append(visible, c)
+		}
+	}
+
+	categorised := false
+	for _, c := range visible {
+		if c.Category != "" {
+			categorised = true
+			break
+		}
+	}
+	if !categorised {
+		if len(visible) == 0 {
+			return nil
+		}
+		return []CommitSection{{Commits: visible}}
+	}
+
+	var sections []CommitSection
+	for _, category := range CommitCategories {
+		var commits []Commit
+		for _, c := range visible {
+			if c.Category == category || (category == CategoryOther && !knownCategory(c.Category)) {
+				commits = append(commits, c)
+			}
+		}
+		if len(commits) > 0 {
+			sections = append(sections, CommitSection{Category: category, Commits: commits})
+		}
+	}
+	return sections
+}
+
+func knownCategory(c CommitCategory) bool {
+	for _, category := range CommitCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
 }
 
 type ProjectChangelog struct {
-	URL      string             `json:"url"`
+	URL string `json:"url"`
+	// Notes are added with /note replies in the release tracker.
+	Notes    []string           `json:"notes,omitempty"`
 	Releases []ReleaseChangelog `json:"releases"`
 }
 
@@ -317,6 +760,84 @@ type Changelog struct {
 	DocsURL           string             `json:"docs_url"`
 	KubernetesVersion string             `json:"kubernetes_version,omitempty"`
 	Projects          []ProjectChangelog `json:"projects"`
+	// StartedAt and CompletedAt are the times of the /ok-to-release and
+	// /done replies in ReleaseTracker.
+	StartedAt      time.Time `json:"started_at,omitzero"`
+	CompletedAt    time.Time `json:"completed_at,omitzero"`
+	ReleaseTracker string    `json:"release_tracker,omitempty"`
+	// Notes about the whole product are added with /note * replies.
+	Notes []string `json:"notes,omitempty"`
+	// CollapseThreshold is copied from the changelog filters of the release.
+	CollapseThreshold int `json:"collapse_threshold,omitempty"`
+	// Bots is copied from the changelog filters of the release.
+	Bots []string `json:"bots,omitempty"`
+	// Collapsed is set by Collapse and rendered once for the product.
+	Collapsed []CollapsedCommit `json:"-"`
+	// Contributors and RepoContributors are set by WithContributors.
+	Contributors     []Contributor      `json:"-"`
+	RepoContributors []RepoContributors `json:"-"`
+}
+
+type CollapsedCommit struct {
+	Subject string
+	Repos   []string
+}
+
+// Collapse returns a copy of the changelog where subjects found in at least
+// CollapseThreshold repos are listed once in Collapsed and hidden from the
+// projects.
+func (chlog Changelog) Collapse() Changelog {
+	if chlog.CollapseThreshold <= 1 {
+		return chlog
+	}
+
+	repos := map[string][]string{}
+	var subjects []string
+	for _, p := range chlog.Projects {
+		seen := map[string]bool{}
+		for _, r := range p.Releases {
+			for _, c := range r.Commits {
+				if c.Filtered || seen[c.Subject] {
+					continue
+				}
+				seen[c.Subject] = true
+				if _, ok := repos[c.Subject]; !ok {
+					subjects = append(subjects, c.Subject)
+				}
+				repos[c.Subject] = append(repos[c.Subject], p.URL)
+			}
+		}
+	}
+
+	collapsed := map[string]bool{}
+	out := chlog
+	out.Collapsed = nil
+	for _, subject := range subjects {
+		if len(repos[subject]) >= chlog.CollapseThreshold {
+			collapsed[subject] = true
+			out.Collapsed = append(out.Collapsed, CollapsedCommit{Subject: subject, Repos: repos[subject]})
+		}
+	}
+	if len(collapsed) == 0 {
+		return out
+	}
+
+	out.Projects = make([]ProjectChangelog, len(chlog.Projects))
+	for i, p := range chlog.Projects {
+		releases := make([]ReleaseChangelog, len(p.Releases))
+		for j, r := range p.Releases {
+			commits := make([]Commit, len(r.Commits))
+			for k, c := range r.Commits {
+				c.Filtered = c.Filtered || collapsed[c.Subject]
+				commits[k] = c
+			}
+			r.Commits = commits
+			releases[j] = r
+		}
+		p.Releases = releases
+		out.Projects[i] = p
+	}
+	return out
 }
 
 func (chlog *Changelog) Sort() {
@@ -340,6 +861,59 @@ func (chlog *Changelog) Sort() {
 	}
 }
 
+type ReleaseNote struct {
+	Repo   string
+	Tag    string
+	Commit Commit
+}
+
+// ReleaseNotes returns the release notes of all projects, split into notes
+// that require action from users and highlights. Filtered commits are left
+// out.
+func (chlog Changelog) ReleaseNotes() (actionRequired []ReleaseNote, highlights []ReleaseNote) {
+	for _, p := range chlog.Projects {
+		for _, r := range p.Releases {
+			for _, c := range r.Commits {
+				if c.Filtered || c.ReleaseNote == "" {
+					continue
+				}
+				note := ReleaseNote{Repo: p.URL, Tag: r.Tag, Commit: c}
+				if c.ActionRequired {
+					actionRequired = append(actionRequired, note)
+				} else {
+					highlights = append(highlights, note)
+				}
+			}
+		}
+	}
+	return
+}
+
+// SecurityFixes returns the security fixes of all projects.
+func (chlog Changelog) SecurityFixes() []ReleaseNote {
+	var out []ReleaseNote
+	for _, p := range chlog.Projects {
+		for _, r := range p.Releases {
+			for _, c := range r.Commits {
+				if c.Security {
+					out = append(out, ReleaseNote{Repo: p.URL, Tag: r.Tag, Commit: c})
+				}
+			}
+		}
+	}
+	return out
+}
+
+func (chlog Changelog) ActionRequired() []ReleaseNote {
+	notes, _ := chlog.ReleaseNotes()
+	return notes
+}
+
+func (chlog Changelog) Highlights() []ReleaseNote {
+	_, notes := chlog.ReleaseNotes()
+	return notes
+}
+
 type ReleaseSummary struct {
 	Release           string
 	ReleaseDate       time.Time
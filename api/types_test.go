@@ -0,0 +1,139 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+)
+
+func strP(s string) *string {
+	return &s
+}
+
+func TestReleaseResolve(t *testing.T) {
+	base := Release{
+		ProductLine: "KubeDB",
+		Release:     "v2026.7.10",
+		Projects: []IndependentProjects{
+			{
+				"github.com/kubedb/apimachinery": Project{Tag: strP("v0.66.0")},
+			},
+			{
+				"github.com/kubedb/cli":      Project{Tag: strP("v0.66.0")},
+				"github.com/kubedb/postgres": Project{Tag: strP("v0.66.0")},
+			},
+			{
+				"github.com/kubedb/installer": Project{
+					Tag:      strP("v2026.7.10"),
+					Commands: []string{"make update-charts"},
+				},
+			},
+		},
+	}
+	overlay := Release{
+		Release: "v2026.7.11",
+		Base:    "v2026.7.10",
+		Overrides: &ReleaseOverrides{
+			Tags: map[string]string{
+				"github.com/kubedb/postgres": "v0.66.1",
+			},
+			Commands: map[string][]string{
+				"github.com/kubedb/installer": {"make update-charts", "make gen"},
+			},
+			Steps: map[string][]Step{
+				"github.com/kubedb/installer": {{Run: "make verify", When: "public"}},
+			},
+			Add: []ProjectGroupOverride{
+				{
+					Group: 1,
+					Projects: IndependentProjects{
+						"github.com/kubedb/mysql": Project{Tag: strP("v0.59.1")},
+					},
+				},
+			},
+			Remove: []string{"github.com/kubedb/apimachinery"},
+		},
+	}
+
+	got, err := overlay.Resolve(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ProductLine != "KubeDB" || got.Base != "" || got.Overrides != nil {
+		t.Errorf("unexpected release metadata %+v", got)
+	}
+	if len(got.Projects) != 2 {
+		t.Fatalf("expected empty group to be removed, got %d groups", len(got.Projects))
+	}
+	if tag := *got.Projects[0]["github.com/kubedb/postgres"].Tag; tag != "v0.66.1" {
+		t.Errorf("postgres tag = %s, want v0.66.1", tag)
+	}
+	if tag := *got.Projects[0]["github.com/kubedb/cli"].Tag; tag != "v0.66.0" {
+		t.Errorf("cli tag = %s, want v0.66.0", tag)
+	}
+	if _, ok := got.Projects[0]["github.com/kubedb/mysql"]; !ok {
+		t.Error("mysql was not added")
+	}
+	installer := got.Projects[1]["github.com/kubedb/installer"]
+	if *installer.Tag != "v2026.7.11" {
+		t.Errorf("installer tag = %s, want v2026.7.11", *installer.Tag)
+	}
+	if len(installer.Commands) != 2 {
+		t.Errorf("installer commands were not replaced: %v", installer.Commands)
+	}
+	if len(installer.Steps) != 1 || installer.Steps[0].Run != "make verify" {
+		t.Errorf("installer steps were not replaced: %v", installer.Steps)
+	}
+	if *base.Projects[2]["github.com/kubedb/installer"].Tag != "v2026.7.10" {
+		t.Error("base release was modified")
+	}
+
+	overlay.Overrides.Tags["github.com/kubedb/unknown"] = "v0.1.0"
+	if _, err := overlay.Resolve(base); err == nil {
+		t.Error("expected error for unknown repo")
+	}
+}
+
+func TestReleaseValidateChangelogFilters(t *testing.T) {
+	r := Release{
+		Release: "v2026.7.10",
+		ChangelogFilters: &ChangelogFilters{
+			Subjects: []string{"^Update deps$", "^Prepare for release ("},
+		},
+	}
+	if err := r.Validate(); err == nil {
+		t.Error("expected invalid subject filter to be rejected")
+	}
+	r.ChangelogFilters.Subjects = r.ChangelogFilters.Subjects[:1]
+	if err := r.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReleaseValidateTrainRefs(t *testing.T) {
+	r := Release{
+		Release: "v2026.7.10",
+		Projects: []IndependentProjects{
+			{
+				"github.com/kubedb/installer": Project{Tag: strP("${kubedb.KUBEDB_OPERATOR_TAG}")},
+			},
+		},
+	}
+	if err := r.Validate(); err != nil {
+		t.Errorf("unresolved train reference should be validated once resolved: %v", err)
+	}
+}
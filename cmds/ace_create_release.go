@@ -24,7 +24,6 @@ import (
 
 	"github.com/google/go-github/v45/github"
 	"github.com/spf13/cobra"
-	"gomodules.xyz/semvers"
 )
 
 func NewCmdAceCreateRelease() *cobra.Command {
@@ -57,6 +56,7 @@ func CreateAceReleaseFile() api.Release {
 	return api.Release{
 		ProductLine: "ACE",
 		Release:     releaseNumber,
+		HideDocs:    hideDocs,
 		// DocsURLTemplate:   "https://appscode.com/docs/%s",
 		KubernetesVersion: "1.28+",
 		Projects: []api.IndependentProjects{
@@ -141,6 +141,7 @@ func CreateAceReleaseFile() api.Release {
 					Commands: []string{
 						"./hack/scripts/import-crds.sh",
 						"make chart-kube-ui-server CHART_VERSION=${RELEASE} APP_VERSION=${KUBEOPS_UI_SERVER_TAG} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL}",
+						UpdateChartAnnotationsCmd(),
 						"./hack/scripts/update-chart-dependencies.sh",
 						"./hack/scripts/update-catalog.sh",
 					},
@@ -173,6 +174,7 @@ func CreateAceReleaseFile() api.Release {
 						"make chart-website CHART_VERSION=${RELEASE} APP_VERSION=${RELEASE} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL}",
 						// opscenter-features
 						"make chart-opscenter-features CHART_VERSION=${RELEASE} APP_VERSION=${APPSCODE_CLOUD_UI_WIZARDS_TAG} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL}",
+						UpdateChartAnnotationsCmd(),
 						"go run ./cmd/update-version/main.go",
 						"./hack/scripts/update-chart-dependencies.sh",
 
@@ -217,14 +219,16 @@ func CreateAceReleaseFile() api.Release {
 				"github.com/kubedb/website": api.Project{
 					Tag:           github.String(releaseNumber + "+akp"),
 					ReleaseBranch: "master",
-					Commands: lib.AppendIf(
-						[]string{
-							"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
-							"make assets docs-platform",
+					Commands: []string{
+						"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
+						"make assets docs-platform",
+					},
+					Steps: []api.Step{
+						{
+							Run:  "make set-platform-version VERSION=${RELEASE}",
+							When: "!hideDocs && public",
 						},
-						!hideDocs && semvers.IsPublicRelease(releaseNumber),
-						"make set-platform-version VERSION=${RELEASE}",
-					),
+					},
 					Changelog: api.SkipChangelog,
 				},
 			},
@@ -0,0 +1,84 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/appscodelabs/release-automaton/lib"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCmdChangelog() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "changelog",
+		Short:             "Changelog commands",
+		DisableAutoGenTag: true,
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.AddCommand(NewCmdChangelogBetween())
+	return cmd
+}
+
+/*
+	release-automaton changelog between v2025.10.17 v2026.7.10 \
+	  --releases-dir=/Users/tamal/go/src/kubedb.dev/CHANGELOG/releases \
+	  --output=md
+*/
+func NewCmdChangelogBetween() *cobra.Command {
+	releasesDir := changelogRoot
+	output := "md"
+	cmd := &cobra.Command{
+		Use:               "between <from> <to>",
+		Short:             "Print the consolidated changelog of the releases after <from> up to <to>",
+		Args:              cobra.ExactArgs(2),
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			changelogs, err := lib.LoadAllChangelogs(releasesDir)
+			if err != nil {
+				return err
+			}
+			r, err := lib.ChangelogBetween(changelogs, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			var data []byte
+			switch output {
+			case "md":
+				data, err = lib.RenderTemplate("changelog-between.tpl", r)
+			case "json":
+				data, err = lib.MarshalJson(r)
+			default:
+				return fmt.Errorf("unknown output format %s", output)
+			}
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&releasesDir, "releases-dir", releasesDir, "Directory with the releases/<version>/CHANGELOG.json files")
+	cmd.Flags().StringVarP(&output, "output", "o", output, "Output format, one of md or json")
+	return cmd
+}
@@ -24,7 +24,6 @@ import (
 
 	"github.com/google/go-github/v45/github"
 	"github.com/spf13/cobra"
-	"gomodules.xyz/semvers"
 )
 
 func NewCmdKubeDBCreateRelease() *cobra.Command {
@@ -57,6 +56,7 @@ func CreateKubeDBReleaseFile() api.Release {
 	return api.Release{
 		ProductLine:       "KubeDB",
 		Release:           releaseNumber,
+		HideDocs:          hideDocs,
 		DocsURLTemplate:   "https://kubedb.com/docs/%s",
 		KubernetesVersion: "1.28+",
 		Projects: []api.IndependentProjects{
@@ -325,6 +325,7 @@ func CreateKubeDBReleaseFile() api.Release {
 						"make chart-kubedb-provider-aws CHART_VERSION=${RELEASE} APP_VERSION=${KUBEDB_PROVIDER_AWS_TAG} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL}",
 						"make chart-kubedb-provider-azure CHART_VERSION=${RELEASE} APP_VERSION=${KUBEDB_PROVIDER_AZURE_TAG} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL}",
 						"make chart-kubedb-provider-gcp CHART_VERSION=${RELEASE} APP_VERSION=${KUBEDB_PROVIDER_GCP_TAG} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL}",
+						UpdateChartAnnotationsCmd(),
 
 						"./hack/scripts/update-chart-dependencies.sh",
 						"sudo make bundle TAG=${RELEASE} VERSION=${RELEASE}",
@@ -366,14 +367,16 @@ func CreateKubeDBReleaseFile() api.Release {
 				"github.com/kubedb/website": api.Project{
 					Tag:           github.String(releaseNumber),
 					ReleaseBranch: "master",
-					Commands: lib.AppendIf(
-						[]string{
-							"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
-							"make docs",
+					Commands: []string{
+						"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
+						"make docs",
+					},
+					Steps: []api.Step{
+						{
+							Run:  "make set-operator-version VERSION=${TAG}",
+							When: "!hideDocs && public",
 						},
-						!hideDocs && semvers.IsPublicRelease(releaseNumber),
-						"make set-operator-version VERSION=${TAG}",
-					),
+					},
 					Changelog: api.SkipChangelog,
 				},
 			},
@@ -24,7 +24,6 @@ import (
 
 	"github.com/google/go-github/v45/github"
 	"github.com/spf13/cobra"
-	"gomodules.xyz/semvers"
 )
 
 func NewCmdKubeStashCreateRelease() *cobra.Command {
@@ -57,6 +56,7 @@ func CreateKubeStashReleaseFile() api.Release {
 	return api.Release{
 		ProductLine:       "KubeStash",
 		Release:           releaseNumber,
+		HideDocs:          hideDocs,
 		DocsURLTemplate:   "https://kubestash.com/docs/%s",
 		KubernetesVersion: "1.28+",
 		Projects: []api.IndependentProjects{
@@ -103,6 +103,7 @@ func CreateKubeStashReleaseFile() api.Release {
 
 						"make update-charts CHART_VERSION=${RELEASE} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL}",
 						"make chart-kubestash-operator CHART_VERSION=${KUBESTASH_KUBESTASH_TAG} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL}",
+						UpdateChartAnnotationsCmd(),
 
 						"make refresh",
 					},
@@ -139,14 +140,16 @@ func CreateKubeStashReleaseFile() api.Release {
 				"github.com/kubestash/website": api.Project{
 					Tag:           github.String(releaseNumber),
 					ReleaseBranch: "master",
-					Commands: lib.AppendIf(
-						[]string{
-							"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
-							"make docs",
+					Commands: []string{
+						"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
+						"make docs",
+					},
+					Steps: []api.Step{
+						{
+							Run:  "make set-version VERSION=${TAG}",
+							When: "!hideDocs && public",
 						},
-						!hideDocs && semvers.IsPublicRelease(releaseNumber),
-						"make set-version VERSION=${TAG}",
-					),
+					},
 					Changelog: api.SkipChangelog,
 				},
 			},
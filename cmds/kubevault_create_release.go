@@ -24,7 +24,6 @@ import (
 
 	"github.com/google/go-github/v45/github"
 	"github.com/spf13/cobra"
-	"gomodules.xyz/semvers"
 )
 
 func NewCmdKubeVaultCreateRelease() *cobra.Command {
@@ -57,6 +56,7 @@ func CreateKubeVaultReleaseFile() api.Release {
 	return api.Release{
 		ProductLine:       "KubeVault",
 		Release:           releaseNumber,
+		HideDocs:          hideDocs,
 		DocsURLTemplate:   "https://kubevault.com/docs/%s",
 		KubernetesVersion: "1.28+",
 		Projects: []api.IndependentProjects{
@@ -103,6 +103,7 @@ func CreateKubeVaultReleaseFile() api.Release {
 						"make update-charts CHART_VERSION=${RELEASE} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL}",
 						"make chart-kubevault-operator CHART_VERSION=${KUBEVAULT_OPERATOR_TAG} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL}",
 						"make chart-kubevault-webhook-server CHART_VERSION=${KUBEVAULT_OPERATOR_TAG} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL}",
+						UpdateChartAnnotationsCmd(),
 
 						"make refresh",
 					},
@@ -139,14 +140,16 @@ func CreateKubeVaultReleaseFile() api.Release {
 				"github.com/kubevault/website": api.Project{
 					Tag:           github.String(releaseNumber),
 					ReleaseBranch: "master",
-					Commands: lib.AppendIf(
-						[]string{
-							"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
-							"make docs",
+					Commands: []string{
+						"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
+						"make docs",
+					},
+					Steps: []api.Step{
+						{
+							Run:  "make set-version VERSION=${TAG}",
+							When: "!hideDocs && public",
 						},
-						!hideDocs && semvers.IsPublicRelease(releaseNumber),
-						"make set-version VERSION=${TAG}",
-					),
+					},
 					Changelog: api.SkipChangelog,
 				},
 			},
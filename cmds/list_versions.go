@@ -17,11 +17,9 @@ limitations under the License.
 package cmds
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 
-	"github.com/appscodelabs/release-automaton/api"
+	"github.com/appscodelabs/release-automaton/lib"
 
 	"github.com/spf13/cobra"
 	"gomodules.xyz/semvers"
@@ -43,18 +41,12 @@ func NewCmdListVersions() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&relFile, "release-file", relFile, "Path to environment file")
+	cmd.Flags().StringVar(&relFile, "release-file", relFile, releaseFileUsage)
 	return cmd
 }
 
 func listReleaseVersions(relFile string) error {
-	data, err := os.ReadFile(relFile)
-	if err != nil {
-		return err
-	}
-
-	var release api.Release
-	err = json.Unmarshal(data, &release)
+	release, err := lib.LoadRelease(newSession(), relFile)
 	if err != nil {
 		return err
 	}
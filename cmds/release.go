@@ -29,7 +29,11 @@ func NewCmdRelease() *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(NewCmdReleaseEnv())
+	cmd.AddCommand(NewCmdReleaseFeed())
 	cmd.AddCommand(NewCmdReleaseReadme())
+	cmd.AddCommand(NewCmdReleaseResolve())
 	cmd.AddCommand(NewCmdReleaseRun())
+	cmd.AddCommand(NewCmdReleaseVerifySignatures())
 	return cmd
 }
@@ -0,0 +1,163 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/appscodelabs/release-automaton/api"
+	"github.com/appscodelabs/release-automaton/lib"
+
+	"github.com/spf13/cobra"
+)
+
+/*
+	release-automaton release env \
+	  --release-file=releases/v2026.7.10/release.json \
+	  --release-tracker=https://github.com/kubedb/CHANGELOG/pull/123 \
+	  --project=github.com/kubedb/installer
+*/
+func NewCmdReleaseEnv() *cobra.Command {
+	var projectFilter []string
+	cmd := &cobra.Command{
+		Use:               "env",
+		Short:             "Print the variables available to project commands",
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sh := newSession()
+			sh.ShowCMD = false
+
+			var err error
+			release, err = lib.LoadRelease(sh, releaseFile)
+			if err != nil {
+				return err
+			}
+			err = release.Validate()
+			if err != nil {
+				return err
+			}
+			setReleaseEnvVars(sh)
+
+			fmt.Println("# Release")
+			printVars(envVars, nil)
+
+			filter := map[string]bool{}
+			for _, repoURL := range projectFilter {
+				filter[repoURL] = true
+			}
+			for _, projects := range release.Projects {
+				for _, repoURL := range lib.Keys(projects) {
+					if len(filter) > 0 && !filter[repoURL] {
+						continue
+					}
+					project := projects[repoURL]
+					if project.Tag != nil {
+						printProjectEnv(repoURL, *project.Tag, project)
+					}
+					for _, tag := range lib.Keys(project.Tags) {
+						printProjectEnv(repoURL, tag, project)
+					}
+				}
+			}
+			for _, repoURL := range lib.Keys(release.ExternalProjects) {
+				if len(filter) > 0 && !filter[repoURL] {
+					continue
+				}
+				printProjectEnv(repoURL, "", release.ExternalProjects[repoURL])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&releaseFile, "release-file", "", releaseFileUsage)
+	cmd.Flags().StringVar(&releaseTracker, "release-tracker", "", "URL of release tracker pull request")
+	cmd.Flags().StringSliceVar(&projectFilter, "project", nil, "Only show these projects (repo urls)")
+	return cmd
+}
+
+func printProjectEnv(repoURL, tag string, project api.ProjectMeta) {
+	owner, repo := lib.ParseRepoURL(repoURL)
+	vars := projectEnvVars(repoURL, tag, filepath.Join(api.Workspace, owner, repo))
+
+	fmt.Println()
+	if tag != "" {
+		fmt.Printf("# %s@%s\n", repoURL, tag)
+	} else {
+		fmt.Printf("# %s\n", repoURL)
+	}
+	printVars(vars, envVars)
+
+	for _, c := range project.GetCommands() {
+		out, missing, err := lib.ExpandVars(c, vars)
+		if err != nil {
+			fmt.Printf("$ %s\n  ! %v\n", c, err)
+			continue
+		}
+		fmt.Printf("$ %s\n", out)
+		if len(missing) > 0 {
+			fmt.Printf("  ! unresolved: %s\n", strings.Join(missing, ", "))
+		}
+	}
+
+	cond := stepConditionVars(tag)
+	for _, step := range project.GetSteps() {
+		stepVars := map[string]string{}
+		for k, v := range step.Env {
+			stepVars[k], _, _ = lib.ExpandVars(v, vars)
+		}
+		stepVars = lib.MergeMaps(stepVars, vars)
+		out, missing, err := lib.ExpandVars(step.Run, stepVars)
+		if err != nil {
+			fmt.Printf("$ %s\n  ! %v\n", step.Run, err)
+			continue
+		}
+		fmt.Printf("$ %s\n", out)
+		if step.When != "" {
+			ok, err := lib.EvalCondition(step.When, cond)
+			switch {
+			case err != nil:
+				fmt.Printf("  ! %v\n", err)
+			case !ok:
+				fmt.Printf("  # skipped, %s is false\n", step.When)
+			default:
+				fmt.Printf("  # runs, %s is true\n", step.When)
+			}
+		}
+		if len(missing) > 0 {
+			fmt.Printf("  ! unresolved: %s\n", strings.Join(missing, ", "))
+		}
+	}
+}
+
+// printVars prints vars in sorted order, skipping entries that are
+// identical in shared.
+func printVars(vars, shared map[string]string) {
+	keys := make([]string, 0, len(vars))
+	for k, v := range vars {
+		if sv, ok := shared[k]; ok && sv == v {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("%s=%s\n", k, vars[k])
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/appscodelabs/release-automaton/api"
+	"github.com/appscodelabs/release-automaton/lib"
+
+	"github.com/spf13/cobra"
+)
+
+/*
+	release-automaton release feed \
+	  --base-url=https://raw.githubusercontent.com/kubedb/CHANGELOG/master/releases
+*/
+func NewCmdReleaseFeed() *cobra.Command {
+	baseURL := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", os.Getenv("GITHUB_REPOSITORY"), api.BranchMaster, api.ReleasesDir)
+	cmd := &cobra.Command{
+		Use:               "feed",
+		Short:             "Generate Atom and JSON feeds and a Keep a Changelog CHANGELOG.md of all releases",
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			changelogs, err := lib.LoadAllChangelogs(changelogRoot)
+			if err != nil {
+				return err
+			}
+			if len(changelogs) == 0 {
+				return fmt.Errorf("no CHANGELOG.json found in %s", changelogRoot)
+			}
+			productLine := changelogs[0].ProductLine
+			baseURL = strings.TrimSuffix(baseURL, "/")
+
+			data, err := lib.AtomFeed(productLine, baseURL+"/atom.xml", changelogs)
+			if err != nil {
+				return err
+			}
+			err = os.WriteFile(filepath.Join(changelogRoot, "atom.xml"), data, 0o644)
+			if err != nil {
+				return err
+			}
+
+			data, err = lib.JSONFeed(productLine, baseURL+"/feed.json", changelogs)
+			if err != nil {
+				return err
+			}
+			err = os.WriteFile(filepath.Join(changelogRoot, "feed.json"), data, 0o644)
+			if err != nil {
+				return err
+			}
+
+			lib.WriteChangelogMarkdown(filepath.Join(scriptRoot, "CHANGELOG.md"), "keep-a-changelog.tpl", lib.NewKeepAChangelog(productLine, changelogs))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&baseURL, "base-url", baseURL, "URL where the feeds in the releases directory are published")
+	return cmd
+}
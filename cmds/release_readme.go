@@ -60,34 +60,19 @@ func GenerateTable() api.ReleaseTable {
 		panic(err)
 	}
 
-	entries, err := os.ReadDir(changelogRoot)
+	changelogs, err := lib.LoadAllChangelogs(changelogRoot)
 	if err != nil {
 		panic(err)
 	}
-	for _, fi := range entries {
-		if !fi.IsDir() {
-			continue
-		}
-		filename := filepath.Join(changelogRoot, fi.Name(), "CHANGELOG.json")
-		if lib.Exists(filename) {
-			var chlog api.Changelog
-			data, err = os.ReadFile(filename)
-			if err != nil {
-				panic(err)
-			}
-			err = json.Unmarshal(data, &chlog)
-			if err != nil {
-				panic(err)
-			}
-			table.Releases = append(table.Releases, api.ReleaseSummary{
-				Release:           chlog.Release,
-				ReleaseDate:       chlog.ReleaseDate,
-				KubernetesVersion: chlog.KubernetesVersion,
-				ReleaseURL:        path.Join(chlog.ReleaseProjectURL, "releases", "tag", chlog.Release),
-				ChangelogURL:      path.Join("/", api.ReleasesDir, chlog.Release, "README.md"),
-				DocsURL:           chlog.DocsURL,
-			})
-		}
+	for _, chlog := range changelogs {
+		table.Releases = append(table.Releases, api.ReleaseSummary{
+			Release:           chlog.Release,
+			ReleaseDate:       chlog.ReleaseDate,
+			KubernetesVersion: chlog.KubernetesVersion,
+			ReleaseURL:        path.Join(chlog.ReleaseProjectURL, "releases", "tag", chlog.Release),
+			ChangelogURL:      path.Join("/", api.ReleasesDir, chlog.Release, "README.md"),
+			DocsURL:           chlog.DocsURL,
+		})
 	}
 
 	// Now keep the full releases and last rc
@@ -0,0 +1,59 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/appscodelabs/release-automaton/lib"
+
+	"github.com/spf13/cobra"
+)
+
+/*
+	release-automaton release resolve \
+	  --release-file=releases/v2026.7.11/release.json
+*/
+func NewCmdReleaseResolve() *cobra.Command {
+	var relFile string
+	cmd := &cobra.Command{
+		Use:               "resolve",
+		Short:             "Print the flattened release file after applying its base release",
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sh := newSession()
+			sh.ShowCMD = false // keep stdout parseable
+			rel, err := lib.LoadRelease(sh, relFile)
+			if err != nil {
+				return err
+			}
+			err = rel.Validate()
+			if err != nil {
+				return err
+			}
+			data, err := lib.MarshalJson(rel)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(data))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&relFile, "release-file", relFile, releaseFileUsage)
+	return cmd
+}
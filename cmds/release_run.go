@@ -18,12 +18,10 @@ package cmds
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"maps"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
@@ -37,10 +35,8 @@ import (
 	"gomodules.xyz/envsubst"
 	shell "gomodules.xyz/go-sh"
 	"gomodules.xyz/oneliners"
-	"gomodules.xyz/semvers"
 	stringz "gomodules.xyz/x/strings"
 	"k8s.io/apimachinery/pkg/util/sets"
-	"sigs.k8s.io/yaml"
 )
 
 var (
@@ -48,6 +44,10 @@ var (
 	releaseFile    string
 	releaseTracker string
 	commentId      int64
+	trainProduct   string // set when several products of a train share the release tracker
+	useGraphQL     bool
+	prStates       map[api.PullRequestReplyData]lib.PullRequestState // only fetched with --graphql
+	prErrors       map[api.PullRequestReplyData]error
 
 	empty          = struct{}{}
 	scriptRoot, _  = os.Getwd()
@@ -73,46 +73,43 @@ func NewCmdReleaseRun() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&releaseFile, "release-file", "", "Path of release file (local file or url is accepted)")
+	cmd.Flags().StringVar(&releaseFile, "release-file", "", releaseFileUsage)
 	cmd.Flags().StringVar(&releaseTracker, "release-tracker", "", "URL of release tracker pull request")
 	cmd.Flags().Int64Var(&commentId, "comment-id", 0, "Comment Id that triggered this run")
+	cmd.Flags().BoolVar(&useGraphQL, "graphql", false, "Use the GitHub GraphQL API to fetch the state of the release tracker and pull requests")
 	return cmd
 }
 
 func runAutomaton() {
-	sh := shell.NewSession()
-	sh.ShowCMD = true
-	sh.PipeFail = true
-	sh.PipeStdErrors = true
+	sh := newSession()
 
 	err := os.RemoveAll(api.Workspace)
 	if err != nil {
 		panic(err)
 	}
 
-	data, err := os.ReadFile(releaseFile)
+	release, err = lib.LoadRelease(sh, releaseFile)
 	if err != nil {
 		panic(err)
 	}
 
-	err = yaml.Unmarshal(data, &release)
+	err = release.Validate()
 	if err != nil {
 		panic(err)
 	}
 
-	err = release.Validate()
+	removeSigningKey, err := lib.ConfigureSigning(sh, release.Signing)
 	if err != nil {
 		panic(err)
 	}
+	defer removeSigningKey()
+
+	setReleaseEnvVars(sh)
 
 	for _, projects := range release.Projects {
 		for repoURL, project := range projects {
 			if project.Tag != nil {
 				repoVersion[repoURL] = *project.Tag
-				lib.SetTagEnv(sh, envVars, repoURL, *project.Tag)
-				if project.Key != "" {
-					envVars[lib.Key2EnvKey(project.Key)] = *project.Tag
-				}
 			}
 			if project.ReadyToTag {
 				replies = api.MergeReplies(replies, api.Reply{
@@ -126,59 +123,38 @@ func runAutomaton() {
 		}
 	}
 
-	vRelease := semver.MustParse(release.Release)
-	if strings.HasPrefix(vRelease.Prerelease(), "alpha.") || strings.HasPrefix(vRelease.Prerelease(), "beta.") {
-		envVars["CHART_REGISTRY"] = api.TestChartRegistry
-		envVars["CHART_REGISTRY_URL"] = api.TestChartRegistryURL
-
-		envVars["UI_REGISTRY"] = api.TestUIRegistry
-		envVars["UI_REGISTRY_URL"] = api.TestUIRegistryURL
-
-		envVars["BUNDLE_REGISTRY"] = api.TestBundleRegistry
-		envVars["BUNDLE_REGISTRY_URL"] = api.TestBundleRegistryURL
-	} else {
-		envVars["CHART_REGISTRY"] = api.StableChartRegistry
-		envVars["CHART_REGISTRY_URL"] = api.StableChartRegistryURL
-
-		envVars["UI_REGISTRY"] = api.StableUIRegistry
-		envVars["UI_REGISTRY_URL"] = api.StableUIRegistryURL
-
-		envVars["BUNDLE_REGISTRY"] = api.StableBundleRegistry
-		envVars["BUNDLE_REGISTRY_URL"] = api.StableBundleRegistryURL
-	}
-
 	releaseOwner, releaseRepo, releasePR := lib.ParsePullRequestURL(releaseTracker)
 
 	gh, err := lib.NewGitHubClient()
 	if err != nil {
 		panic(err)
 	}
-	pr, _, err := gh.PullRequests.Get(context.TODO(), releaseOwner, releaseRepo, releasePR)
+	var gql *lib.GraphQLClient
+	var tracker *lib.TrackerState
+	if useGraphQL {
+		gql = lib.NewGraphQLClient(gh)
+		tracker, err = gql.GetTrackerState(context.TODO(), releaseOwner, releaseRepo, releasePR)
+	} else {
+		tracker, err = lib.GetTrackerState(gh, releaseOwner, releaseRepo, releasePR)
+	}
 	if err != nil {
 		panic(err)
 	}
-	if pr.GetDraft() {
+	if tracker.Draft {
 		fmt.Println("Release tracker pr is currently in draft mode")
 		return
 	}
-	if pr.GetState() != "open" {
+	if tracker.State != "open" {
 		fmt.Println("Release tracker pr is not open")
 		return
 	}
-	approved, err := lib.PRApproved(gh, releaseOwner, releaseRepo, releasePR)
-	if err != nil {
-		panic(err)
-	}
-	if !approved {
+	if !tracker.Approved {
 		fmt.Println("PR must be approved to continue")
 		return
 	}
 
 	// Build state
-	prComments, err := lib.ListComments(context.TODO(), gh, releaseOwner, releaseRepo, releasePR)
-	if err != nil {
-		panic(err)
-	}
+	prComments := tracker.Comments
 	if commentId > 0 {
 		// This is done to avoid using any comments that was added after this action was triggered
 		idx := -1
@@ -193,8 +169,18 @@ func runAutomaton() {
 		}
 	}
 
+	var startedAt, completedAt time.Time
 	for _, comment := range prComments {
-		replies = api.MergeReplies(replies, lib.ParseComment(comment.GetBody())...)
+		commentReplies := lib.ParseComment(comment.GetBody())
+		for _, reply := range commentReplies {
+			if reply.Type == api.OkToRelease && startedAt.IsZero() {
+				startedAt = comment.GetCreatedAt()
+			}
+			if reply.Type == api.Done && reply.Key().B == trainProduct && completedAt.IsZero() {
+				completedAt = comment.GetCreatedAt()
+			}
+		}
+		replies = api.MergeReplies(replies, commentReplies...)
 	}
 	for _, reply := range replies[api.Go] {
 		modCache[reply.Go.ModulePath] = lib.GoImport{
@@ -202,21 +188,21 @@ func runAutomaton() {
 			VCSRoot:  reply.Go.VCSRoot,
 		}
 	}
-
 	if _, ok := replies[api.OkToRelease]; !ok {
 		fmt.Println("Not /ok-to-release yet")
 		return
 	}
-	if _, ok := replies[api.Done]; ok {
+	if replies.IsDone(trainProduct) {
 		fmt.Println("Already done!")
+		// fix the release date
+		err = recordReleaseTimeline(sh, startedAt, completedAt)
+		if err != nil {
+			panic(err)
+		}
 		return
 	}
 
-	existingLabels, err := lib.ListLabelsByIssue(context.TODO(), gh, releaseOwner, releaseRepo, releasePR)
-	if err != nil {
-		panic(err)
-	}
-	if existingLabels.Has(api.LabelLocked) {
+	if tracker.Labels.Has(api.LabelLocked) {
 		fmt.Println("Already locked, exiting ...")
 		return
 	}
@@ -234,6 +220,45 @@ func runAutomaton() {
 		}
 	}()
 
+	// persist the release timeline and /note replies in the changelog
+	{
+		dir := filepath.Join(changelogRoot, release.Release)
+		lib.UpdateChangelogTimeline(dir, release, releaseTracker, startedAt, time.Time{})
+
+		notes := make([]api.NoteReplyData, 0, len(replies[api.Note]))
+		for _, reply := range replies[api.Note] {
+			notes = append(notes, *reply.Note)
+		}
+		lib.UpdateChangelogNotes(dir, release, notes)
+		if lib.AnyRepoModified(scriptRoot, sh) {
+			err = lib.CommitAnyRepo(scriptRoot, sh, "", "Update changelog")
+			if err != nil {
+				panic(err)
+			}
+			err = lib.PushAnyRepo(scriptRoot, sh, false)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	if gql != nil {
+		prs := pendingPRs()
+		prStates, prErrors, err = gql.GetPullRequestStates(context.TODO(), prs)
+		if err != nil {
+			panic(err)
+		}
+		for _, pr := range prs {
+			if err, ok := prErrors[pr]; ok {
+				fmt.Printf("%s#%d: %v\n", pr.Repo, pr.Number, err)
+				continue
+			}
+			state := prStates[pr]
+			fmt.Printf("%s#%d: %s, mergeable: %s, checks: %s\n", pr.Repo, pr.Number, state.State, state.Mergeable, state.CheckStatus)
+		}
+	}
+	detectMergedPRs(gh, sh)
+
 	for groupIdx, projects := range release.Projects {
 		firstGroup := groupIdx == 0
 
@@ -312,7 +337,7 @@ func runAutomaton() {
 		readyToTag := sets.NewString()
 		if firstGroup {
 			for repoURL, project := range projects {
-				if notTagged.Has(repoURL) && len(project.Commands) == 0 {
+				if notTagged.Has(repoURL) && len(project.Commands) == 0 && len(project.Steps) == 0 {
 					readyToTag.Insert(repoURL)
 					notTagged.Delete(repoURL)
 				}
@@ -355,13 +380,7 @@ func runAutomaton() {
 			project := projects[repoURL]
 			if project.Tag == nil && len(project.Tags) == 0 {
 				err = PrepareExternalProject(gh, sh, releaseTracker, repoURL, project)
-				chlog := lib.LoadChangelog(filepath.Join(changelogRoot, release.Release), release)
-				switch project.Changelog {
-				case api.StandaloneWebsiteChangelog:
-					lib.WriteChangelogMarkdown(filepath.Join(changelogRoot, release.Release, "docs_changelog.md"), "standalone-changelog.tpl", chlog)
-				case api.SharedWebsiteChangelog:
-					lib.WriteChangelogMarkdown(filepath.Join(changelogRoot, release.Release, "docs_changelog.md"), "shared-changelog.tpl", chlog)
-				}
+				writeDocsChangelogAs(project.Changelog, lib.LoadChangelog(filepath.Join(changelogRoot, release.Release), release))
 				if lib.AnyRepoModified(scriptRoot, sh) {
 					err = lib.CommitAnyRepo(scriptRoot, sh, "", "Update changelog")
 					if err != nil {
@@ -383,7 +402,7 @@ func runAutomaton() {
 		// Tag the repos in readyToTag
 		for _, repoURL := range readyToTag.UnsortedList() {
 			oneliners.FILE()
-			err = ReleaseProject(sh, releaseTracker, repoURL, projects[repoURL])
+			err = ReleaseProject(gh, sh, releaseTracker, repoURL, projects[repoURL])
 			if err != nil {
 				panic(err)
 			}
@@ -455,9 +474,20 @@ func runAutomaton() {
 		}
 	}
 
+	// the /done comment of the bot may not trigger another run, so the
+	// release date is fixed now
+	err = recordReleaseTimeline(sh, startedAt, time.Now())
+	if err != nil {
+		panic(err)
+	}
+
 	oneliners.FILE("COMMENTS>>>>", strings.Join(comments, "\n"))
 	{
-		comments = append(comments, string(api.Done))
+		if trainProduct != "" {
+			comments = append(comments, fmt.Sprintf("%s %s", api.Done, trainProduct))
+		} else {
+			comments = append(comments, string(api.Done))
+		}
 		comments = lib.UniqComments(comments)
 		_, _, err := gh.Issues.CreateComment(context.TODO(), releaseOwner, releaseRepo, releasePR, &github.IssueComment{
 			Body: github.String(strings.Join(comments, "\n")),
@@ -469,16 +499,114 @@ func runAutomaton() {
 	}
 }
 
+// recordReleaseTimeline records the timeline of the release in its changelog.
+// The changelogs are rendered again if the release date changed.
+func recordReleaseTimeline(sh *shell.Session, startedAt, completedAt time.Time) error {
+	dir := filepath.Join(changelogRoot, release.Release)
+	if lib.UpdateChangelogTimeline(dir, release, releaseTracker, startedAt, completedAt) {
+		writeDocsChangelog(lib.LoadChangelog(dir, release))
+	}
+	if lib.AnyRepoModified(scriptRoot, sh) {
+		err := lib.CommitAnyRepo(scriptRoot, sh, "", "Update changelog")
+		if err != nil {
+			return err
+		}
+		return lib.PushAnyRepo(scriptRoot, sh, false)
+	}
+	return nil
+}
+
+// setReleaseEnvVars collects the tag, version, hash and registry variables
+// shared by the commands of every project in the release.
+func setReleaseEnvVars(sh *shell.Session) {
+	for _, projects := range release.Projects {
+		for repoURL, project := range projects {
+			if project.Tag != nil {
+				lib.SetTagEnv(sh, envVars, repoURL, *project.Tag)
+				if project.Key != "" {
+					envVars[lib.Key2EnvKey(project.Key)] = *project.Tag
+				}
+			}
+		}
+	}
+
+	if release.Channel() == api.ChannelTesting {
+		envVars["CHART_REGISTRY"] = api.TestChartRegistry
+		envVars["CHART_REGISTRY_URL"] = api.TestChartRegistryURL
+
+		envVars["UI_REGISTRY"] = api.TestUIRegistry
+		envVars["UI_REGISTRY_URL"] = api.TestUIRegistryURL
+
+		envVars["BUNDLE_REGISTRY"] = api.TestBundleRegistry
+		envVars["BUNDLE_REGISTRY_URL"] = api.TestBundleRegistryURL
+	} else {
+		envVars["CHART_REGISTRY"] = api.StableChartRegistry
+		envVars["CHART_REGISTRY_URL"] = api.StableChartRegistryURL
+
+		envVars["UI_REGISTRY"] = api.StableUIRegistry
+		envVars["UI_REGISTRY_URL"] = api.StableUIRegistryURL
+
+		envVars["BUNDLE_REGISTRY"] = api.StableBundleRegistry
+		envVars["BUNDLE_REGISTRY_URL"] = api.StableBundleRegistryURL
+	}
+}
+
+// projectEnvVars returns the variables available to the commands of a project.
+// tag is empty for external projects.
+func projectEnvVars(repoURL, tag, workspace string) map[string]string {
+	vars := map[string]string{
+		"SCRIPT_ROOT":     scriptRoot,
+		"WORKSPACE":       workspace,
+		"PRODUCT_LINE":    release.ProductLine,
+		"RELEASE":         release.Release,
+		"RELEASE_TRACKER": releaseTracker,
+	}
+	if tag != "" {
+		vars[lib.RepoURL2TagEnvKey(repoURL)] = tag
+		vars["TAG"] = tag
+		vars["TAG_WITHOUT_V_PREFIX"] = strings.TrimPrefix(tag, "v")
+	}
+	return lib.MergeMaps(vars, envVars)
+}
+
+// writeDocsChangelog renders the docs_changelog.md of the release for the
+// project that publishes the changelog on the website, if any.
+func writeDocsChangelog(chlog api.Changelog) {
+	for _, projects := range release.Projects {
+		for _, project := range projects {
+			if project.Changelog == api.StandaloneWebsiteChangelog || project.Changelog == api.SharedWebsiteChangelog {
+				writeDocsChangelogAs(project.Changelog, chlog)
+				return
+			}
+		}
+	}
+}
+
+func writeDocsChangelogAs(status api.ChangelogStatus, chlog api.Changelog) {
+	filename := filepath.Join(changelogRoot, release.Release, "docs_changelog.md")
+	switch status {
+	case api.StandaloneWebsiteChangelog:
+		lib.WriteChangelogMarkdown(filename, "standalone-changelog.tpl", lib.PrepareChangelog(changelogRoot, chlog))
+	case api.SharedWebsiteChangelog:
+		lib.WriteChangelogMarkdown(filename, "shared-changelog.tpl", lib.PrepareChangelog(changelogRoot, chlog))
+	}
+}
+
 // gitCloneWithToken runs `git clone` against a plain HTTPS URL, passing the
 // credentials via `--config http.<host>.extraheader=...` instead of embedding
 // them in the URL. This keeps the token out of the session log AND out of the
 // cloned repo's `origin` URL in .git/config. The extraheader config is
 // persisted into the new repo's local config so subsequent fetch/push against
-// `origin` (same host) authenticate automatically.
+// `origin` (same host) authenticate automatically. Callers must configure
+// the auth again for existing clones, see lib.ConfigureGitHubAuthFor.
 func gitCloneWithToken(sh *shell.Session, repoURL string, extraArgs ...string) error {
 	cloneURL := fmt.Sprintf("https://%s.git", repoURL)
-	creds := base64.StdEncoding.EncodeToString([]byte(os.Getenv(api.GitHubUserKey) + ":" + os.Getenv(api.GitHubTokenKey)))
-	authConfig := "http.https://github.com/.extraheader=AUTHORIZATION: basic " + creds
+	owner, _ := lib.ParseRepoURL(repoURL)
+	header, err := lib.GitHubAuthHeader(owner)
+	if err != nil {
+		return err
+	}
+	authConfig := lib.GitHubExtraHeaderKey + "=" + header
 
 	args := make([]any, 0, 3+len(extraArgs)+1)
 	args = append(args, "clone", "--config", authConfig)
@@ -492,7 +620,7 @@ func gitCloneWithToken(sh *shell.Session, repoURL string, extraArgs ...string) e
 	if prev {
 		fmt.Printf("$ git clone %s %s\n", strings.Join(extraArgs, " "), cloneURL)
 	}
-	err := sh.Command("git", args...).Run()
+	err = sh.Command("git", args...).Run()
 	sh.ShowCMD = prev
 	return err
 }
@@ -528,7 +656,13 @@ func UpdateChartIndex(gh *github.Client, sh *shell.Session, repoURL string) erro
 	wdCur = filepath.Join(wdCur, repo)
 	sh.SetDir(wdCur)
 
-	err = sh.Command("git", "fetch", "origin").Run()
+	// a clone of an earlier run may hold an expired token
+	err = lib.ConfigureGitHubAuthFor(sh, wdCur, owner)
+	if err != nil {
+		return err
+	}
+
+	err = lib.FetchRepo(sh, "origin")
 	if err != nil {
 		return err
 	}
@@ -588,6 +722,12 @@ func PrepareProject(gh *github.Client, sh *shell.Session, releaseTracker, repoUR
 	wdCur = filepath.Join(wdCur, repo)
 	sh.SetDir(wdCur)
 
+	// a clone of an earlier run may hold an expired token
+	err = lib.ConfigureGitHubAuthFor(sh, wdCur, owner)
+	if err != nil {
+		return err
+	}
+
 	modPath := DetectGoMod(wdCur)
 	if modPath != "" {
 		gm := lib.GoImport{
@@ -676,7 +816,13 @@ func PrepareProject(gh *github.Client, sh *shell.Session, releaseTracker, repoUR
 		if modPath != "" {
 			AppendGo(modPath)
 		}
-		return nil
+		if !ok {
+			// /tagged is only posted once the tags are done
+			return nil
+		}
+		// An earlier run may have pushed the tags but failed to record
+		// their changelog or publish their GitHub releases.
+		return publishMissingGitHubReleases(gh, sh, releaseTracker, repoURL, project, lib.Keys(tags))
 	}
 
 	usesCherryPick := project.Tags != nil && project.Tag == nil
@@ -696,16 +842,7 @@ func PrepareProject(gh *github.Client, sh *shell.Session, releaseTracker, repoUR
 
 		// -----------------------
 
-		vars := lib.MergeMaps(map[string]string{
-			lib.RepoURL2TagEnvKey(repoURL): tag,
-			"SCRIPT_ROOT":                  scriptRoot,
-			"WORKSPACE":                    sh.Getwd(),
-			"TAG":                          tag,
-			"TAG_WITHOUT_V_PREFIX":         strings.TrimPrefix(tag, "v"),
-			"PRODUCT_LINE":                 release.ProductLine,
-			"RELEASE":                      release.Release,
-			"RELEASE_TRACKER":              releaseTracker,
-		}, envVars)
+		vars := projectEnvVars(repoURL, tag, sh.Getwd())
 
 		headBranch := fmt.Sprintf("%s-%s", release.Release, branch)
 
@@ -748,6 +885,10 @@ func PrepareProject(gh *github.Client, sh *shell.Session, releaseTracker, repoUR
 				return err
 			}
 		}
+		err = runSteps(sh, project.Steps, vars, tag)
+		if err != nil {
+			return err
+		}
 
 		if lib.RepoModified(sh) {
 			messages := []string{
@@ -802,7 +943,7 @@ func AppendGo(modPath string) {
 	comments = append(comments, fmt.Sprintf(`%s %s %s %s`, api.Go, gm.RepoRoot, modPath, gm.VCSRoot))
 }
 
-func ReleaseProject(sh *shell.Session, releaseTracker, repoURL string, project api.Project) error {
+func ReleaseProject(gh *github.Client, sh *shell.Session, releaseTracker, repoURL string, project api.Project) error {
 	if project.Tags != nil && project.Tag != nil {
 		return fmt.Errorf("repo %s is provided an invalid project configuration which uses both tag and tags", repoURL)
 	}
@@ -837,6 +978,12 @@ func ReleaseProject(sh *shell.Session, releaseTracker, repoURL string, project a
 	wdCur = filepath.Join(wdCur, repo)
 	sh.SetDir(wdCur)
 
+	// a clone of an earlier run may hold an expired token
+	err = lib.ConfigureGitHubAuthFor(sh, wdCur, owner)
+	if err != nil {
+		return err
+	}
+
 	modPath := DetectGoMod(wdCur)
 	if modPath != "" {
 		gm := lib.GoImport{
@@ -881,7 +1028,13 @@ func ReleaseProject(sh *shell.Session, releaseTracker, repoURL string, project a
 		if modPath != "" {
 			AppendGo(modPath)
 		}
-		return nil
+		if !ok {
+			// /tagged is only posted once the tags are done
+			return nil
+		}
+		// An earlier run may have pushed the tags but failed to record
+		// their changelog or publish their GitHub releases.
+		return publishMissingGitHubReleases(gh, sh, releaseTracker, repoURL, project, lib.Keys(tags))
 	}
 
 	usesCherryPick := project.Tags != nil && project.Tag == nil
@@ -894,6 +1047,34 @@ func ReleaseProject(sh *shell.Session, releaseTracker, repoURL string, project a
 			return err
 		}
 
+		// incompatible API changes are checked before tagging, if enforced
+		var apiChanges []api.ModuleAPIChanges
+		apiChecked := project.APICompatibility == api.SkipAPICompatibility
+		tagRepo := func() error {
+			if !apiChecked && project.APICompatibility == api.EnforceAPICompatibility {
+				base, err := changelogBase(sh, project, vTag, "HEAD")
+				if err != nil {
+					return err
+				}
+				if base != "" {
+					// only incompatible changes block tagging, not a failure of gorelease
+					apiChanges, err = lib.CheckAPICompatibility(sh, wdCur, base)
+					if err != nil {
+						fmt.Printf("incomplete API compatibility check of %s: %v\n", repoURL, err)
+					}
+				}
+				apiChecked = true
+				if lib.BreaksAPICompatibility(base, vTag, apiChanges) {
+					return fmt.Errorf("repo %s can't be tagged %s, it has incompatible API changes since %s", repoURL, tag, base)
+				}
+			}
+			err := lib.TagRepo(sh, tag, "ProductLine: "+release.ProductLine, "Release: "+release.Release, "Release-tracker: "+releaseTracker)
+			if err != nil {
+				return err
+			}
+			return lib.PushRepo(sh, true)
+		}
+
 		// detect branch
 		if usesCherryPick {
 			// remote branch must already exist
@@ -901,48 +1082,11 @@ func ReleaseProject(sh *shell.Session, releaseTracker, repoURL string, project a
 				return fmt.Errorf("repo %s is missing branch for tag %s", repoURL, tag)
 			}
 		} else {
-			if project.ReleaseBranch != "" {
-				vars := lib.MergeMaps(map[string]string{
-					lib.RepoURL2TagEnvKey(repoURL): tag,
-					"SCRIPT_ROOT":                  scriptRoot,
-					"WORKSPACE":                    sh.Getwd(),
-					"TAG":                          tag,
-					"PRODUCT_LINE":                 release.ProductLine,
-					"RELEASE":                      release.Release,
-					"RELEASE_TRACKER":              releaseTracker,
-				}, envVars)
-				branch, err = envsubst.EvalMap(project.ReleaseBranch, vars)
-				if err != nil {
-					return err
-				}
-				tags[tag] = branch
-			} else if vTag.Patch() > 0 { // PATCH release
-				if vTag.Prerelease() != "" {
-					panic(fmt.Errorf("version %s is invalid because it is a patch release but includes a pre-release component", tag))
-				}
-
-				patchBranch := fmt.Sprintf("release-%d.%d.%d", vTag.Major(), vTag.Minor(), vTag.Patch())
-				if lib.RemoteBranchExists(sh, patchBranch) {
-					branch = patchBranch
-				} else {
-					minorBranch := fmt.Sprintf("release-%d.%d", vTag.Major(), vTag.Minor())
-					if lib.RemoteBranchExists(sh, minorBranch) {
-						branch = minorBranch
-					}
-				}
-
-				if branch == "" {
-					if vTag.Major() == 0 && vTag.Minor() == 0 {
-						branch = "release-0.0"
-					} else {
-						return fmt.Errorf("repo %s is missing branch for tag %s", repoURL, tag)
-					}
-				}
-				tags[tag] = branch
-			} else {
-				branch = fmt.Sprintf("release-%d.%d", vTag.Major(), vTag.Minor())
-				tags[tag] = branch
+			branch, err = projectReleaseBranch(sh, "origin", repoURL, project, vTag)
+			if err != nil {
+				return err
 			}
+			tags[tag] = branch
 		}
 
 		// -----------------------
@@ -966,11 +1110,7 @@ func ReleaseProject(sh *shell.Session, releaseTracker, repoURL string, project a
 				return err
 			}
 			if existingTag, ok := lib.IsTagged(sh); !ok || existingTag != tag {
-				err = lib.TagRepo(sh, tag, "ProductLine: "+release.ProductLine, "Release: "+release.Release, "Release-tracker: "+releaseTracker)
-				if err != nil {
-					return err
-				}
-				err = lib.PushRepo(sh, true)
+				err = tagRepo()
 				if err != nil {
 					return err
 				}
@@ -993,11 +1133,7 @@ func ReleaseProject(sh *shell.Session, releaseTracker, repoURL string, project a
 			if existingTag, ok := lib.IsTagged(sh); ok && existingTag != tag {
 				return fmt.Errorf("%s branch %s is already tagged as %s, did you forget to cherry pick?", repoURL, branch, existingTag)
 			} else if !ok { // if untagged
-				err = lib.TagRepo(sh, tag, "ProductLine: "+release.ProductLine, "Release: "+release.Release, "Release-tracker: "+releaseTracker)
-				if err != nil {
-					return err
-				}
-				err = lib.PushRepo(sh, true)
+				err = tagRepo()
 				if err != nil {
 					return err
 				}
@@ -1018,11 +1154,7 @@ func ReleaseProject(sh *shell.Session, releaseTracker, repoURL string, project a
 						return err
 					}
 				}
-				err = lib.TagRepo(sh, tag, "ProductLine: "+release.ProductLine, "Release: "+release.Release, "Release-tracker: "+releaseTracker)
-				if err != nil {
-					return err
-				}
-				err = lib.PushRepo(sh, true)
+				err = tagRepo()
 				if err != nil {
 					return err
 				}
@@ -1042,76 +1174,186 @@ func ReleaseProject(sh *shell.Session, releaseTracker, repoURL string, project a
 				if err != nil {
 					return err
 				}
-				err = lib.TagRepo(sh, tag, "ProductLine: "+release.ProductLine, "Release: "+release.Release, "Release-tracker: "+releaseTracker)
-				if err != nil {
-					return err
-				}
-				err = lib.PushRepo(sh, true)
+				err = tagRepo()
 				if err != nil {
 					return err
 				}
 			}
 		}
 
-		if project.Changelog == api.AddToChangelog {
-			tags, err := lib.ListTags(sh)
+		base, commits, err := changelogCommits(gh, sh, repoURL, project, vTag)
+		if err != nil {
+			return err
+		}
+		if !apiChecked && base != "" {
+			apiChanges, err = lib.CheckAPICompatibility(sh, wdCur, base)
 			if err != nil {
-				return err
+				fmt.Printf("incomplete API compatibility report of %s: %v\n", repoURL, err)
 			}
-			tagSet := sets.NewString(tags...)
-			tagSet.Insert(tag)
+		}
+		rc := api.ReleaseChangelog{
+			Tag:        tag,
+			Base:       base,
+			Commits:    commits,
+			APIChanges: apiChanges,
+		}
+		err = recordReleaseChangelog(sh, repoURL, project, rc)
+		if err != nil {
+			return err
+		}
+		err = lib.PublishGitHubRelease(gh, lib.GitHubReleaseNotes{
+			ProductLine: release.ProductLine,
+			Release:     release.Release,
+			ReleaseURL:  lib.ProductReleaseURL(releaseTracker, release.Release),
+			Tracker:     releaseTracker,
+			Repo:        repoURL,
+			Changelog:   rc,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-			vs := make([]*semver.Version, 0, tagSet.Len())
-			for _, x := range tagSet.UnsortedList() {
-				v, err := semver.NewVersion(x)
+	// add comments to release repo
+	{
+		comments = append(comments, fmt.Sprintf("%s %s", api.Tagged, repoURL))
+		if modPath != "" {
+			AppendGo(modPath)
+		}
+	}
+
+	return nil
+}
+
+// recordReleaseChangelog adds the changelog of a tag to CHANGELOG.json of the
+// release, if the project is part of the changelog.
+func recordReleaseChangelog(sh *shell.Session, repoURL string, project api.Project, rc api.ReleaseChangelog) error {
+	if project.Changelog != api.AddToChangelog {
+		return nil
+	}
+	lib.UpdateChangelog(filepath.Join(changelogRoot, release.Release), release, repoURL, rc)
+	if lib.AnyRepoModified(scriptRoot, sh) {
+		err := lib.CommitAnyRepo(scriptRoot, sh, "", "Update changelog")
+		if err != nil {
+			return err
+		}
+		return lib.PushAnyRepo(scriptRoot, sh, false)
+	}
+	return nil
+}
+
+// publishMissingGitHubReleases finishes the tags pushed by an earlier run of
+// this release tracker that failed before recording their changelog or
+// publishing their GitHub release. A tag belongs to this release if it is in
+// the changelog of the release or its message names this release tracker.
+// Tags of earlier releases, eg, of repos carried over unchanged, are skipped.
+func publishMissingGitHubReleases(gh *github.Client, sh *shell.Session, releaseTracker, repoURL string, project api.Project, tags []string) error {
+	chlog := lib.LoadChangelog(filepath.Join(changelogRoot, release.Release), release)
+	fetched := false
+	for _, tag := range tags {
+		rc, found := findReleaseChangelog(chlog, repoURL, tag)
+		if !found {
+			if !fetched {
+				err := lib.FetchRepo(sh, "--tags", "origin")
 				if err != nil {
-					fmt.Printf("skipping bad version %s, error: %v", x, err)
-					continue
-				}
-				// filter out lower importance tags
-				if semvers.AtLeastAsImp(vTag, v) {
-					vs = append(vs, v)
+					return err
 				}
+				fetched = true
 			}
-			sort.Sort(semvers.SemverCollection(vs))
-
-			tagIdx := -1
-			for idx, vs := range vs {
-				if vs.Equal(vTag) {
-					tagIdx = idx
-					break
-				}
+			msg, err := lib.TagMessage(sh, tag)
+			if err != nil {
+				return err
+			}
+			if !lib.HasMessageLine(msg, "Release-tracker: "+releaseTracker) {
+				continue
 			}
 
-			var commits []api.Commit
-			if tagIdx == 0 {
-				commits = lib.ListCommits(sh, lib.FirstCommit(sh), vs[tagIdx].Original())
-			} else {
-				commits = lib.ListCommits(sh, vs[tagIdx-1].Original(), vs[tagIdx].Original())
+			vTag, err := semver.NewVersion(tag)
+			if err != nil {
+				return err
 			}
-			lib.UpdateChangelog(filepath.Join(changelogRoot, release.Release), release, repoURL, tag, commits)
-			if lib.AnyRepoModified(scriptRoot, sh) {
-				err = lib.CommitAnyRepo(scriptRoot, sh, "", "Update changelog")
-				if err != nil {
-					return err
-				}
-				err = lib.PushAnyRepo(scriptRoot, sh, false)
-				if err != nil {
-					return err
-				}
+			base, commits, err := changelogCommits(gh, sh, repoURL, project, vTag)
+			if err != nil {
+				return err
+			}
+			rc = api.ReleaseChangelog{
+				Tag:     tag,
+				Base:    base,
+				Commits: commits,
+			}
+			err = recordReleaseChangelog(sh, repoURL, project, rc)
+			if err != nil {
+				return err
 			}
 		}
+
+		exists, err := lib.GitHubReleaseExists(gh, repoURL, tag)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		err = lib.PublishGitHubRelease(gh, lib.GitHubReleaseNotes{
+			ProductLine: release.ProductLine,
+			Release:     release.Release,
+			ReleaseURL:  lib.ProductReleaseURL(releaseTracker, release.Release),
+			Tracker:     releaseTracker,
+			Repo:        repoURL,
+			Changelog:   rc,
+		})
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// add comments to release repo
-	{
-		comments = append(comments, fmt.Sprintf("%s %s", api.Tagged, repoURL))
-		if modPath != "" {
-			AppendGo(modPath)
+func findReleaseChangelog(chlog api.Changelog, repoURL, tag string) (api.ReleaseChangelog, bool) {
+	for _, p := range chlog.Projects {
+		if p.URL != repoURL {
+			continue
+		}
+		for _, rc := range p.Releases {
+			if rc.Tag == tag {
+				return rc, true
+			}
 		}
 	}
+	return api.ReleaseChangelog{}, false
+}
 
-	return nil
+// changelogBase returns the nearest ancestor tag of ref of at least the same
+// importance as vTag. It is empty if there is none.
+func changelogBase(sh *shell.Session, project api.Project, vTag *semver.Version, ref string) (string, error) {
+	ancestors, err := lib.AncestorTags(sh, ref)
+	if err != nil {
+		return "", err
+	}
+	return lib.PreviousTag(vTag, ancestors, project.SameReleaseLine), nil
+}
+
+// changelogCommits lists the commits included in tag vTag, starting from
+// the nearest ancestor tag of at least the same importance. The base tag is
+// returned too. It is empty if the commits start at the first commit.
+func changelogCommits(gh *github.Client, sh *shell.Session, repoURL string, project api.Project, vTag *semver.Version) (string, []api.Commit, error) {
+	tag := vTag.Original()
+	base, err := changelogBase(sh, project, vTag, tag)
+	if err != nil {
+		return "", nil, err
+	}
+
+	start := base
+	if start == "" {
+		start = lib.FirstCommit(sh)
+	}
+	commits := lib.ListCommits(sh, start, tag)
+	commits = lib.EnrichCommits(gh, repoURL, start, tag, commits)
+	commits = lib.CategorizeCommits(gh, repoURL, commits)
+	commits = lib.CollectReleaseNotes(gh, repoURL, commits)
+	commits = lib.MarkSecurityFixes(commits)
+	commits, err = lib.FilterCommits(release.ChangelogFilters, commits)
+	return base, commits, err
 }
 
 func PrepareExternalProject(gh *github.Client, sh *shell.Session, releaseTracker, repoURL string, project api.ProjectMeta) error {
@@ -1145,15 +1387,15 @@ func PrepareExternalProject(gh *github.Client, sh *shell.Session, releaseTracker
 	wdCur = filepath.Join(wdCur, repo)
 	sh.SetDir(wdCur)
 
+	// a clone of an earlier run may hold an expired token
+	err = lib.ConfigureGitHubAuthFor(sh, wdCur, owner)
+	if err != nil {
+		return err
+	}
+
 	// -----------------------
 
-	vars := lib.MergeMaps(map[string]string{
-		"SCRIPT_ROOT":     scriptRoot,
-		"WORKSPACE":       sh.Getwd(),
-		"PRODUCT_LINE":    release.ProductLine,
-		"RELEASE":         release.Release,
-		"RELEASE_TRACKER": releaseTracker,
-	}, envVars)
+	vars := projectEnvVars(repoURL, "", sh.Getwd())
 
 	headBranch := fmt.Sprintf("%s-%s", release.ProductLine, release.Release)
 
@@ -1197,6 +1439,10 @@ func PrepareExternalProject(gh *github.Client, sh *shell.Session, releaseTracker
 			return err
 		}
 	}
+	err = runSteps(sh, project.GetSteps(), vars, "")
+	if err != nil {
+		return err
+	}
 
 	if lib.RepoModified(sh) {
 		messages := []string{
@@ -1373,6 +1619,155 @@ func UpdateGoMod(sh *shell.Session, dir string) {
 	}
 }
 
+// projectReleaseBranch detects the release branch a tag of a non cherry pick
+// project is created from. remote is either a remote name or a repo URL.
+func projectReleaseBranch(sh *shell.Session, remote, repoURL string, project api.Project, vTag *semver.Version) (string, error) {
+	tag := vTag.Original()
+	if project.ReleaseBranch != "" {
+		vars := projectEnvVars(repoURL, tag, sh.Getwd())
+		return envsubst.EvalMap(project.ReleaseBranch, vars)
+	}
+	if vTag.Patch() == 0 {
+		return fmt.Sprintf("release-%d.%d", vTag.Major(), vTag.Minor()), nil
+	}
+
+	// PATCH release
+	if vTag.Prerelease() != "" {
+		return "", fmt.Errorf("version %s is invalid because it is a patch release but includes a pre-release component", tag)
+	}
+	patchBranch := fmt.Sprintf("release-%d.%d.%d", vTag.Major(), vTag.Minor(), vTag.Patch())
+	if lib.RemoteBranchExistsIn(sh, remote, patchBranch) {
+		return patchBranch, nil
+	}
+	minorBranch := fmt.Sprintf("release-%d.%d", vTag.Major(), vTag.Minor())
+	if lib.RemoteBranchExistsIn(sh, remote, minorBranch) {
+		return minorBranch, nil
+	}
+	if vTag.Major() == 0 && vTag.Minor() == 0 {
+		return "release-0.0", nil
+	}
+	return "", fmt.Errorf("repo %s is missing branch for tag %s", repoURL, tag)
+}
+
+// detectMergedPRs checks the release prs of projects that are not tagged
+// yet. A pr merged into master or a release branch is recorded with a
+// /ready-to-tag or /cherry-picked reply using its merge commit. A pr closed
+// without merging is flagged once with a /pr-closed reply, unless a later
+// pr was opened for the same repo. Its merge is still detected if it is
+// reopened.
+func detectMergedPRs(gh *github.Client, sh *shell.Session) {
+	for _, pr := range pendingPRs() {
+		project, _ := findProject(pr.Repo)
+		prURL := fmt.Sprintf("https://%s/pull/%d", pr.Repo, pr.Number)
+
+		// a deleted pr must not block the release of the other projects
+		state, ok := prStates[pr]
+		if err, failed := prErrors[pr]; failed {
+			fmt.Printf("!!! failed to fetch pr %s: %v\n", prURL, err)
+			continue
+		} else if !ok {
+			var err error
+			state, err = lib.GetPullRequestState(gh, pr)
+			if err != nil {
+				fmt.Printf("!!! failed to fetch pr %s: %v\n", prURL, err)
+				continue
+			}
+		}
+
+		switch {
+		case state.Merged && project.Tag != nil && isTagBaseBranch(sh, pr.Repo, project, state.BaseRef):
+			if replies, ok = api.AppendReplyIfMissing(replies, api.Reply{
+				Type: api.ReadyToTag,
+				ReadyToTag: &api.ReadyToTagReplyData{
+					Repo:           pr.Repo,
+					MergeCommitSHA: state.MergeCommitSHA,
+				},
+			}); ok {
+				comments = append(comments, fmt.Sprintf("%s %s %s", api.ReadyToTag, pr.Repo, state.MergeCommitSHA))
+			}
+		case state.Merged && project.Tag == nil && stringz.Contains(lib.Values(project.Tags), state.BaseRef):
+			if replies, ok = api.AppendReplyIfMissing(replies, api.Reply{
+				Type: api.CherryPicked,
+				CherryPicked: &api.CherryPickedReplyData{
+					Repo:           pr.Repo,
+					Branch:         state.BaseRef,
+					MergeCommitSHA: state.MergeCommitSHA,
+				},
+			}); ok {
+				comments = append(comments, fmt.Sprintf("%s %s %s %s", api.CherryPicked, pr.Repo, state.BaseRef, state.MergeCommitSHA))
+			}
+		case state.Merged:
+			fmt.Printf("pr %s merged into unexpected branch %s\n", prURL, state.BaseRef)
+		case state.State == "CLOSED" && hasLaterPR(pr):
+			fmt.Printf("pr %s was closed and replaced by a later pr\n", prURL)
+		case state.State == "CLOSED":
+			fmt.Printf("!!! pr %s was closed without merging, reopen it or post /ready-to-tag or /cherry-picked manually\n", prURL)
+			if replies, ok = api.AppendReplyIfMissing(replies, api.Reply{
+				Type:     api.PRClosed,
+				PRClosed: &pr,
+			}); ok {
+				comments = append(comments, fmt.Sprintf("%s %s", api.PRClosed, prURL))
+			}
+		}
+	}
+}
+
+// pendingPRs returns the release prs of the projects that are neither tagged
+// nor ready to tag yet.
+func pendingPRs() []api.PullRequestReplyData {
+	done := sets.NewString()
+	for _, reply := range replies[api.Tagged] {
+		done.Insert(reply.Tagged.Repo)
+	}
+	for _, reply := range replies[api.ReadyToTag] {
+		done.Insert(reply.ReadyToTag.Repo)
+	}
+
+	var prs []api.PullRequestReplyData
+	for _, reply := range replies[api.PR] {
+		project, ok := findProject(reply.PR.Repo)
+		if !ok || done.Has(reply.PR.Repo) || (project.Tag == nil && len(project.Tags) == 0) {
+			continue
+		}
+		prs = append(prs, *reply.PR)
+	}
+	return prs
+}
+
+// hasLaterPR reports whether a release pr was opened for the repo of pr
+// after it.
+func hasLaterPR(pr api.PullRequestReplyData) bool {
+	for _, reply := range replies[api.PR] {
+		if reply.PR.Repo == pr.Repo && reply.PR.Number > pr.Number {
+			return true
+		}
+	}
+	return false
+}
+
+// isTagBaseBranch reports whether the release pr of a Tag project was merged
+// into master or into the release branch the tag is created from.
+func isTagBaseBranch(sh *shell.Session, repoURL string, project api.Project, branch string) bool {
+	if branch == api.BranchMaster {
+		return true
+	}
+	vTag, err := semver.NewVersion(*project.Tag)
+	if err != nil {
+		return false
+	}
+	releaseBranch, err := projectReleaseBranch(sh, "https://"+repoURL+".git", repoURL, project, vTag)
+	return err == nil && branch == releaseBranch
+}
+
+func findProject(repoURL string) (api.Project, bool) {
+	for _, projects := range release.Projects {
+		if project, ok := projects[repoURL]; ok {
+			return project, true
+		}
+	}
+	return api.Project{}, false
+}
+
 func findRepoTags(reg string) ([]string, bool) {
 	for _, projects := range release.Projects {
 		for repoURL, project := range projects {
@@ -0,0 +1,123 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/appscodelabs/release-automaton/api"
+	"github.com/appscodelabs/release-automaton/lib"
+
+	"github.com/Masterminds/semver/v3"
+	"gomodules.xyz/envsubst"
+	shell "gomodules.xyz/go-sh"
+	"gomodules.xyz/semvers"
+)
+
+// stepConditionVars returns the variables available to the when condition
+// of a step.
+func stepConditionVars(tag string) map[string]string {
+	return map[string]string{
+		"release":    release.Release,
+		"tag":        tag,
+		"prerelease": semver.MustParse(release.Release).Prerelease(),
+		"public":     strconv.FormatBool(semvers.IsPublicRelease(release.Release)),
+		"channel":    release.Channel(),
+		"hideDocs":   strconv.FormatBool(release.HideDocs),
+	}
+}
+
+// runSteps runs the steps of a project from the project workspace, ie, the
+// current directory of sh.
+func runSteps(sh *shell.Session, steps []api.Step, vars map[string]string, tag string) error {
+	cond := stepConditionVars(tag)
+
+	wd := sh.Getwd()
+	defer sh.SetDir(wd)
+	defer sh.SetTimeout(0)
+
+	for _, step := range steps {
+		ok, err := lib.EvalCondition(step.When, cond)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.Printf("skipping step %q, condition %q is false", step.Run, step.When)
+			continue
+		}
+
+		err = runStep(sh, step, vars, wd)
+		if err != nil {
+			if step.ContinueOnError {
+				log.Printf("ignoring failed step %q: %v", step.Run, err)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func runStep(sh *shell.Session, step api.Step, vars map[string]string, wd string) error {
+	stepVars := make(map[string]string, len(step.Env))
+	for k, v := range step.Env {
+		v, err := envsubst.EvalMap(v, vars)
+		if err != nil {
+			return err
+		}
+		stepVars[k] = v
+	}
+	stepVars = lib.MergeMaps(stepVars, vars)
+
+	cmd, err := envsubst.EvalMap(step.Run, stepVars)
+	if err != nil {
+		return err
+	}
+
+	dir := wd
+	if step.Dir != "" {
+		dir, err = envsubst.EvalMap(step.Dir, stepVars)
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(wd, dir)
+		}
+	}
+	sh.SetDir(dir)
+
+	var timeout time.Duration
+	if step.Timeout != "" {
+		timeout, err = time.ParseDuration(step.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout for step %q: %v", step.Run, err)
+		}
+	}
+	sh.SetTimeout(timeout)
+
+	for attempt := 0; ; attempt++ {
+		err = lib.Execute(sh, cmd, stepVars)
+		if err == nil || attempt >= step.Retries {
+			return err
+		}
+		log.Printf("step %q failed (attempt %d/%d): %v", step.Run, attempt+1, step.Retries+1, err)
+	}
+}
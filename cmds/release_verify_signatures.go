@@ -0,0 +1,160 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/appscodelabs/release-automaton/api"
+	"github.com/appscodelabs/release-automaton/lib"
+
+	"github.com/spf13/cobra"
+	shell "gomodules.xyz/go-sh"
+)
+
+/*
+	release-automaton release verify-signatures \
+	  --release-file=${SCRIPT_ROOT}/releases/${RELEASE}/release.json \
+	  --release-tracker=https://github.com/kubedb/CHANGELOG/pull/123
+*/
+func NewCmdReleaseVerifySignatures() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "verify-signatures",
+		Short:             "Verify that the tags of a release are signed by an allowed key",
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return verifySignatures()
+		},
+	}
+
+	cmd.Flags().StringVar(&releaseFile, "release-file", "", releaseFileUsage)
+	cmd.Flags().StringVar(&releaseTracker, "release-tracker", "", "URL of release tracker pull request")
+	return cmd
+}
+
+func verifySignatures() error {
+	sh := newSession()
+
+	var err error
+	release, err = lib.LoadRelease(sh, releaseFile)
+	if err != nil {
+		return err
+	}
+	if release.Signing == nil || release.Signing.AllowedSignersFile == "" {
+		return fmt.Errorf("release %s does not configure signing.allowed_signers_file", release.Release)
+	}
+	allowedSignersFile := release.Signing.AllowedSignersFile
+	if !filepath.IsAbs(allowedSignersFile) {
+		allowedSignersFile = filepath.Join(scriptRoot, allowedSignersFile)
+	}
+
+	gh, err := lib.NewGitHubClient()
+	if err != nil {
+		return err
+	}
+	owner, repo, number := lib.ParsePullRequestURL(releaseTracker)
+	prComments, err := lib.ListComments(context.TODO(), gh, owner, repo, number)
+	if err != nil {
+		return err
+	}
+	var trackerReplies api.Replies
+	for _, comment := range prComments {
+		trackerReplies = api.MergeReplies(trackerReplies, lib.ParseComment(comment.GetBody())...)
+	}
+	taggedRepos := map[string]bool{}
+	for _, reply := range trackerReplies[api.Tagged] {
+		taggedRepos[reply.Tagged.Repo] = true
+	}
+
+	keyring, err := lib.ImportAllowedSigners(sh, release.Signing, allowedSignersFile)
+	if keyring != "" {
+		defer os.RemoveAll(keyring)
+	}
+	if err != nil {
+		return err
+	}
+
+	var unsigned []string
+	for _, projects := range release.Projects {
+		for repoURL, project := range projects {
+			if !taggedRepos[repoURL] {
+				continue
+			}
+			var tags []string
+			if project.Tag != nil {
+				tags = append(tags, *project.Tag)
+			} else {
+				tags = lib.Keys(project.Tags)
+			}
+			for _, tag := range tags {
+				err = verifyRemoteTag(sh, repoURL, tag, allowedSignersFile)
+				if err != nil {
+					fmt.Printf("%s %s: %v\n", repoURL, tag, err)
+					unsigned = append(unsigned, repoURL+"@"+tag)
+				} else {
+					fmt.Printf("%s %s: signed\n", repoURL, tag)
+				}
+			}
+		}
+	}
+	if len(unsigned) > 0 {
+		return fmt.Errorf("tags not signed by an allowed key: %s", strings.Join(unsigned, ", "))
+	}
+	return nil
+}
+
+// verifyRemoteTag fetches tag of repoURL into the workspace and verifies its
+// signature.
+func verifyRemoteTag(sh *shell.Session, repoURL, tag, allowedSignersFile string) error {
+	wdOrig := sh.Getwd()
+	defer sh.SetDir(wdOrig)
+
+	owner, repo := lib.ParseRepoURL(repoURL)
+	dir := filepath.Join(api.Workspace, "signatures", owner, repo)
+	if !lib.Exists(filepath.Join(dir, ".git")) {
+		err := os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return err
+		}
+		sh.SetDir(dir)
+		err = sh.Command("git", "init", "--quiet").Run()
+		if err != nil {
+			return err
+		}
+		err = sh.Command("git", "remote", "add", "origin", fmt.Sprintf("https://%s.git", repoURL)).Run()
+		if err != nil {
+			return err
+		}
+	}
+	sh.SetDir(dir)
+	err := lib.ConfigureGitHubAuthFor(sh, dir, owner)
+	if err != nil {
+		return err
+	}
+
+	// git fetch --depth=1 origin refs/tags/<tag>:refs/tags/<tag>
+	ref := "refs/tags/" + tag
+	err = lib.FetchRepo(sh, "--quiet", "--depth=1", "origin", ref+":"+ref)
+	if err != nil {
+		return err
+	}
+	return lib.VerifyTag(sh, release.Signing, allowedSignersFile, tag)
+}
@@ -17,16 +17,28 @@ limitations under the License.
 package cmds
 
 import (
+	"path/filepath"
+
+	"github.com/appscodelabs/release-automaton/api"
+	"github.com/appscodelabs/release-automaton/lib"
+
 	"github.com/spf13/cobra"
 	v "gomodules.xyz/x/version"
 )
 
 func NewRootCmd() *cobra.Command {
+	var templatesDir string
 	rootCmd := &cobra.Command{
 		Use:               "release-automaton [command]",
 		Short:             `release-automaton by AppsCode - Release often`,
 		DisableAutoGenTag: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// templates in .release-automaton/templates of the release tracker repo
+			// overlay the embedded ones, and are overlaid by --templates-dir
+			lib.SetTemplateDirs(filepath.Join(scriptRoot, api.TemplatesDir), templatesDir)
+		},
 	}
+	rootCmd.PersistentFlags().StringVar(&templatesDir, "templates-dir", templatesDir, "Directory with *.tpl files that overlay or replace the embedded changelog templates")
 
 	rootCmd.AddCommand(NewCmdRelease())
 	rootCmd.AddCommand(NewCmdAce())
@@ -36,9 +48,12 @@ func NewRootCmd() *cobra.Command {
 	rootCmd.AddCommand(NewCmdStash())
 	rootCmd.AddCommand(NewCmdVirtualSecrets())
 	rootCmd.AddCommand(NewCmdVoyager())
+	rootCmd.AddCommand(NewCmdTrain())
+	rootCmd.AddCommand(NewCmdChangelog())
 	rootCmd.AddCommand(NewCmdListVersions())
 	rootCmd.AddCommand(NewCmdUpdateAssets())
 	rootCmd.AddCommand(NewCmdUpdateBundles())
+	rootCmd.AddCommand(NewCmdUpdateChartAnnotations())
 	rootCmd.AddCommand(NewCmdUpdateEnvVars())
 	rootCmd.AddCommand(v.NewCmdVersion())
 	return rootCmd
@@ -24,7 +24,6 @@ import (
 
 	"github.com/google/go-github/v45/github"
 	"github.com/spf13/cobra"
-	"gomodules.xyz/semvers"
 )
 
 func NewCmdStashCreateRelease() *cobra.Command {
@@ -68,6 +67,7 @@ func CreateStashReleaseFile() api.Release {
 	return api.Release{
 		ProductLine:       "Stash",
 		Release:           releaseNumber,
+		HideDocs:          hideDocs,
 		DocsURLTemplate:   "https://stash.run/docs/%s",
 		KubernetesVersion: "1.28+",
 		Projects: []api.IndependentProjects{
@@ -295,14 +295,16 @@ func CreateStashReleaseFile() api.Release {
 				"github.com/stashed/website": api.Project{
 					Tag:           github.String(releaseNumber),
 					ReleaseBranch: "master",
-					Commands: lib.AppendIf(
-						[]string{
-							"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
-							"make docs",
+					Commands: []string{
+						"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
+						"make docs",
+					},
+					Steps: []api.Step{
+						{
+							Run:  "make set-version VERSION=${TAG}",
+							When: "!hideDocs && public",
 						},
-						!hideDocs && semvers.IsPublicRelease(releaseNumber),
-						"make set-version VERSION=${TAG}",
-					),
+					},
 					Changelog: api.SkipChangelog,
 				},
 			},
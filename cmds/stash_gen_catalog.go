@@ -26,7 +26,6 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/spf13/cobra"
-	"sigs.k8s.io/yaml"
 	"stash.appscode.dev/installer/catalog"
 )
 
@@ -47,23 +46,20 @@ func NewCmdStashGenCatalog() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&releaseFile, "release-file", "", "Path of release file (local file or url is accepted)")
+	cmd.Flags().StringVar(&releaseFile, "release-file", "", releaseFileUsage)
 	cmd.Flags().StringVar(&catalogFile, "catalog-file", "", "Path to Stash catalog file")
 	return cmd
 }
 
 func generateCatalog() error {
-	data, err := os.ReadFile(releaseFile)
-	if err != nil {
-		return err
-	}
-	err = yaml.Unmarshal(data, &release)
+	var err error
+	release, err = lib.LoadRelease(newSession(), releaseFile)
 	if err != nil {
 		return err
 	}
 
 	var catalog catalog.StashCatalog
-	data, err = os.ReadFile(catalogFile)
+	data, err := os.ReadFile(catalogFile)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,244 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/appscodelabs/release-automaton/api"
+	"github.com/appscodelabs/release-automaton/lib"
+
+	"github.com/google/go-github/v45/github"
+	"github.com/spf13/cobra"
+	shell "gomodules.xyz/go-sh"
+)
+
+var trainFile string
+
+func NewCmdTrain() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "train",
+		Short:             "Release train commands",
+		DisableAutoGenTag: true,
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&trainFile, "train-file", "", "Path of release train file. Accepts the same sources as --release-file")
+	cmd.AddCommand(NewCmdTrainStatus())
+	cmd.AddCommand(NewCmdTrainRun())
+	return cmd
+}
+
+/*
+	release-automaton train status \
+	  --train-file=trains/2026.7/train.json
+*/
+func NewCmdTrainStatus() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "status",
+		Short:             "Show the progress of a release train",
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sh := newSession()
+			sh.ShowCMD = false
+
+			gh, err := lib.NewGitHubClient()
+			if err != nil {
+				return err
+			}
+			train, stops, err := loadTrainStops(sh, gh)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Train %s\n", train.Name)
+			for _, stop := range stops {
+				state := "pending"
+				if stop.done {
+					state = "done"
+				}
+				fmt.Printf("  %-12s %s@%s %s %s\n", stop.product.Name, stop.release.ProductLine, stop.release.Release, state, train.TrackerOf(stop.product))
+				for _, ref := range stop.refs {
+					fmt.Printf("    uses %s.%s = %s\n", ref.Product, ref.Var, ref.Value)
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+/*
+	release-automaton train run \
+	  --train-file=trains/2026.7/train.json \
+	  --release-tracker=https://github.com/kubedb/CHANGELOG/pull/123 \
+	  --comment-id=1
+*/
+func NewCmdTrainRun() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "run",
+		Short:             "Run the release process for the current product of a release train",
+		DisableAutoGenTag: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			runTrain()
+		},
+	}
+
+	cmd.Flags().StringVar(&releaseTracker, "release-tracker", "", "URL of release tracker pull request that triggered this run")
+	cmd.Flags().Int64Var(&commentId, "comment-id", 0, "Comment Id that triggered this run")
+	return cmd
+}
+
+type trainStop struct {
+	product api.TrainProduct
+	release api.Release
+	refs    []lib.TrainRef
+	done    bool
+}
+
+// loadTrainStops loads the release of every product of the train, resolving
+// references to earlier products in order.
+func loadTrainStops(sh *shell.Session, gh *github.Client) (api.ReleaseTrain, []trainStop, error) {
+	train, err := lib.LoadTrain(sh, trainFile)
+	if err != nil {
+		return train, nil, err
+	}
+
+	vars := map[string]map[string]lib.TrainVar{}
+	stops := make([]trainStop, 0, len(train.Products))
+	for _, p := range train.Products {
+		rel, err := lib.LoadRelease(sh, p.ReleaseFile)
+		if err != nil {
+			return train, nil, err
+		}
+		// release files are validated after the train references are resolved
+		rel, refs, err := lib.ResolveTrainRefs(rel, vars)
+		if err != nil {
+			return train, nil, fmt.Errorf("product %s: %v", p.Name, err)
+		}
+		if err = rel.Validate(); err != nil {
+			return train, nil, fmt.Errorf("product %s: %v", p.Name, err)
+		}
+		done, err := trainProductDone(gh, train, p)
+		if err != nil {
+			return train, nil, err
+		}
+		stops = append(stops, trainStop{
+			product: p,
+			release: rel,
+			refs:    refs,
+			done:    done,
+		})
+		vars[p.Name] = lib.TrainVars(rel)
+	}
+	return train, stops, nil
+}
+
+// trainProductDone checks if the release tracker of a product is merged or
+// has a /done reply for it.
+func trainProductDone(gh *github.Client, train api.ReleaseTrain, p api.TrainProduct) (bool, error) {
+	owner, repo, number := lib.ParsePullRequestURL(train.TrackerOf(p))
+	pr, _, err := gh.PullRequests.Get(context.TODO(), owner, repo, number)
+	if err != nil {
+		return false, err
+	}
+	if pr.GetMerged() {
+		return true, nil
+	}
+
+	prComments, err := lib.ListComments(context.TODO(), gh, owner, repo, number)
+	if err != nil {
+		return false, err
+	}
+	var trackerReplies api.Replies
+	for _, comment := range prComments {
+		trackerReplies = api.MergeReplies(trackerReplies, lib.ParseComment(comment.GetBody())...)
+	}
+	if train.SharesTracker(p) {
+		return trackerReplies.IsDone(p.Name), nil
+	}
+	return trackerReplies.IsDone(""), nil
+}
+
+func runTrain() {
+	sh := newSession()
+
+	gh, err := lib.NewGitHubClient()
+	if err != nil {
+		panic(err)
+	}
+	train, stops, err := loadTrainStops(sh, gh)
+	if err != nil {
+		panic(err)
+	}
+
+	idx := -1
+	for i, stop := range stops {
+		if !stop.done {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		fmt.Printf("Train %s is done!\n", train.Name)
+		return
+	}
+	stop := stops[idx]
+	if tracker := train.TrackerOf(stop.product); tracker != releaseTracker {
+		fmt.Printf("Train %s is waiting for %s in %s\n", train.Name, stop.product.Name, tracker)
+		return
+	}
+
+	// Referenced versions must be published before they are used. Only tags
+	// are checked in their repo, other variables, eg, RELEASE, are published
+	// once the referenced product is done.
+	for _, ref := range stop.refs {
+		for _, prev := range stops[:idx] {
+			if prev.product.Name == ref.Product && !prev.done {
+				panic(fmt.Errorf("%s references %s.%s but %s is not done", stop.product.Name, ref.Product, ref.Var, ref.Product))
+			}
+		}
+		if ref.Repo != "" && lib.GetRemoteCommitHash(sh, ref.Repo, ref.Value) == "" {
+			panic(fmt.Errorf("%s references %s.%s but %s is missing tag %s", stop.product.Name, ref.Product, ref.Var, ref.Repo, ref.Value))
+		}
+	}
+
+	filename := filepath.Join(os.TempDir(), "release-automaton", "trains", train.Name, stop.product.Name+".json")
+	err = os.MkdirAll(filepath.Dir(filename), 0o755)
+	if err != nil {
+		panic(err)
+	}
+	data, err := lib.MarshalJson(stop.release)
+	if err != nil {
+		panic(err)
+	}
+	err = os.WriteFile(filename, data, 0o644)
+	if err != nil {
+		panic(err)
+	}
+
+	releaseFile = filename
+	envVars["RELEASE_FILE"] = filename
+	if train.SharesTracker(stop.product) {
+		trainProduct = stop.product.Name
+	}
+	runAutomaton()
+}
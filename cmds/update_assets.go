@@ -54,18 +54,15 @@ func NewCmdUpdateAssets() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&releaseFile, "release-file", "", "Path of release file (local file or url is accepted)")
+	cmd.Flags().StringVar(&releaseFile, "release-file", "", releaseFileUsage)
 	cmd.Flags().StringVar(&repoWorkspace, "workspace", "", "Path to directory containing git repository")
 	cmd.Flags().BoolVar(&hideDoc, "hide", false, "If true, hide docs from website")
 	return cmd
 }
 
 func updateAssets() error {
-	data, err := os.ReadFile(releaseFile)
-	if err != nil {
-		return err
-	}
-	err = yaml.Unmarshal(data, &release)
+	var err error
+	release, err = lib.LoadRelease(newSession(), releaseFile)
 	if err != nil {
 		return err
 	}
@@ -51,18 +51,15 @@ func NewCmdUpdateBundles() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&releaseFile, "release-file", "", "Path of release file (local file or url is accepted)")
+	cmd.Flags().StringVar(&releaseFile, "release-file", "", releaseFileUsage)
 	cmd.Flags().StringVar(&repoWorkspace, "workspace", "", "Path to directory containing git repository")
 	cmd.Flags().StringVar(&chartsDir, "charts-dir", chartsDir, "Directory containing bundles in the workspace")
 	return cmd
 }
 
 func updateBundles() error {
-	data, err := os.ReadFile(releaseFile)
-	if err != nil {
-		return err
-	}
-	err = yaml.Unmarshal(data, &release)
+	var err error
+	release, err = lib.LoadRelease(newSession(), releaseFile)
 	if err != nil {
 		return err
 	}
@@ -143,7 +140,7 @@ func updateBundles() error {
 		}
 
 		// Update bundle.yaml
-		data, err = os.ReadFile(bundleFilename)
+		data, err := os.ReadFile(bundleFilename)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,166 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/appscodelabs/release-automaton/api"
+	"github.com/appscodelabs/release-automaton/lib"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/spf13/cobra"
+	yu "gomodules.xyz/encoding/yaml"
+	ylib "gopkg.in/yaml.v2"
+	"sigs.k8s.io/yaml"
+)
+
+/*
+	release-automaton update-chart-annotations \
+	  --release-file=${SCRIPT_ROOT}/releases/${RELEASE}/release.json \
+	  --changelog-file=${SCRIPT_ROOT}/releases/${RELEASE}/CHANGELOG.json \
+	  --workspace=${WORKSPACE} \
+	  --charts-dir=charts
+*/
+func NewCmdUpdateChartAnnotations() *cobra.Command {
+	var changelogFile string
+	cmd := &cobra.Command{
+		Use:               "update-chart-annotations",
+		Short:             "Update the Artifact Hub annotations of charts from the changelog",
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateChartAnnotations(changelogFile)
+		},
+	}
+
+	cmd.Flags().StringVar(&releaseFile, "release-file", "", releaseFileUsage)
+	cmd.Flags().StringVar(&changelogFile, "changelog-file", "", "Path to CHANGELOG.json of the release. Defaults to CHANGELOG.json next to a local release file")
+	cmd.Flags().StringVar(&repoWorkspace, "workspace", "", "Path to directory containing git repository")
+	cmd.Flags().StringVar(&chartsDir, "charts-dir", chartsDir, "Directory containing charts in the workspace")
+	return cmd
+}
+
+func updateChartAnnotations(changelogFile string) error {
+	var err error
+	release, err = lib.LoadRelease(newSession(), releaseFile)
+	if err != nil {
+		return err
+	}
+
+	if changelogFile == "" {
+		changelogFile = filepath.Join(filepath.Dir(releaseFile), "CHANGELOG.json")
+	}
+	data, err := os.ReadFile(changelogFile)
+	if err != nil {
+		return err
+	}
+	var chlog api.Changelog
+	err = json.Unmarshal(data, &chlog)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %v", changelogFile, err)
+	}
+
+	dir := filepath.Join(repoWorkspace, chartsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range entries {
+		if !fi.IsDir() {
+			continue
+		}
+		chartFilename := filepath.Join(dir, fi.Name(), "Chart.yaml")
+		if !lib.Exists(chartFilename) {
+			continue
+		}
+		err = updateChartAnnotation(chartFilename, chlog)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func updateChartAnnotation(chartFilename string, chlog api.Changelog) error {
+	data, err := os.ReadFile(chartFilename)
+	if err != nil {
+		return err
+	}
+	var ch ylib.MapSlice
+	err = ylib.Unmarshal(data, &ch)
+	if err != nil {
+		return err
+	}
+
+	name, ok, err := yu.NestedString(ch, "name")
+	if err != nil || !ok {
+		return err
+	}
+	repoURL, project, ok := findProjectByChart(name, release)
+	if !ok {
+		return nil
+	}
+
+	var tags []string
+	if project.Tag != nil {
+		tags = append(tags, *project.Tag)
+	} else {
+		tags = lib.Keys(project.Tags)
+	}
+	var prerelease bool
+	for _, tag := range tags {
+		if v, err := semver.NewVersion(tag); err == nil && v.Prerelease() != "" {
+			prerelease = true
+		}
+	}
+
+	var commits []api.Commit
+	for _, p := range chlog.Projects {
+		if p.URL == repoURL {
+			for _, r := range p.Releases {
+				commits = append(commits, r.Commits...)
+			}
+		}
+	}
+
+	if changes := lib.ArtifactHubChanges(repoURL, commits); len(changes) > 0 {
+		changesYAML, err := yaml.Marshal(changes)
+		if err != nil {
+			return err
+		}
+		err = yu.SetNestedField(&ch, string(changesYAML), "annotations", lib.AnnotationArtifactHubChanges)
+		if err != nil {
+			return err
+		}
+	} else {
+		yu.RemoveNestedField(&ch, "annotations", lib.AnnotationArtifactHubChanges)
+	}
+	err = yu.SetNestedField(&ch, strconv.FormatBool(prerelease), "annotations", lib.AnnotationArtifactHubPrerelease)
+	if err != nil {
+		return err
+	}
+
+	data, err = ylib.Marshal(ch)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(chartFilename, data, 0o644)
+}
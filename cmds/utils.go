@@ -22,8 +22,20 @@ import (
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	shell "gomodules.xyz/go-sh"
 )
 
+const releaseFileUsage = "Path of release file. Local file, http(s) url, git::<ref>:<path>, " +
+	"git::<repo-url>//<path>?ref=<ref> and pr::<pr-url>//<path> are accepted, with an optional ?checksum=<type>:<value>"
+
+func newSession() *shell.Session {
+	sh := shell.NewSession()
+	sh.ShowCMD = true
+	sh.PipeFail = true
+	sh.PipeStdErrors = true
+	return sh
+}
+
 func MustTime(t time.Time, e error) time.Time {
 	if e != nil {
 		panic(e)
@@ -64,3 +76,10 @@ func UpdateAssetsCmd(hideDocs bool) string {
 	}
 	return fmt.Sprintf("release-automaton update-assets %s--release-file=${SCRIPT_ROOT}/releases/${RELEASE}/release.json --workspace=${WORKSPACE}", flags)
 }
+
+// UpdateChartAnnotationsCmd builds the `release-automaton update-chart-annotations`
+// command that records the changes of a release in the Artifact Hub annotations
+// of the charts in an installer repo. It must run after the charts are bumped.
+func UpdateChartAnnotationsCmd() string {
+	return "release-automaton update-chart-annotations --release-file=${SCRIPT_ROOT}/releases/${RELEASE}/release.json --workspace=${WORKSPACE}"
+}
@@ -81,6 +81,7 @@ func CreateVirtualSecretsReleaseFile() api.Release {
 						"./hack/scripts/import-crds.sh",
 						"make chart-virtual-secrets-server CHART_VERSION=${RELEASE} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL} APP_VERSION=${VIRTUAL_SECRETS_SERVER_TAG}",
 						"make chart-secrets-store-csi-driver-provider-virtual-secrets CHART_VERSION=${RELEASE} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL} APP_VERSION=${VIRTUAL_SECRETS_CSI_PROVIDER_TAG}",
+						UpdateChartAnnotationsCmd(),
 						"./hack/scripts/update-catalog.sh",
 					},
 				},
@@ -117,14 +118,16 @@ func CreateVirtualSecretsReleaseFile() api.Release {
 					"github.com/virtual-secrets/website": api.Project{
 						Tag:           github.String(releaseNumber),
 						ReleaseBranch: "master",
-						Commands: lib.AppendIf(
-							[]string{
-								"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
-								"make docs",
+						Commands: []string{
+							"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
+							"make docs",
+						},
+						Steps: []api.Step{
+							{
+								Run:  "make set-version VERSION=${TAG}",
+								When: "public",
 							},
-							semvers.IsPublicRelease(releaseNumber),
-							"make set-version VERSION=${TAG}",
-						),
+						},
 						Changelog: api.SkipChangelog,
 					},
 				},
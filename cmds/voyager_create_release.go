@@ -24,7 +24,6 @@ import (
 
 	"github.com/google/go-github/v45/github"
 	"github.com/spf13/cobra"
-	"gomodules.xyz/semvers"
 )
 
 func NewCmdVoyagerCreateRelease() *cobra.Command {
@@ -57,6 +56,7 @@ func CreateVoyagerReleaseFile() api.Release {
 	return api.Release{
 		ProductLine:       "Voyager",
 		Release:           releaseNumber,
+		HideDocs:          hideDocs,
 		DocsURLTemplate:   "https://voyagermesh.com/docs/%s",
 		KubernetesVersion: "1.28+",
 		Projects: []api.IndependentProjects{
@@ -88,6 +88,7 @@ func CreateVoyagerReleaseFile() api.Release {
 						"make chart-gateway-api CHART_VERSION=${RELEASE} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL} APP_VERSION=${VOYAGERMESH_HAPROXY_INGRESS_TAG}",
 						"make chart-voyager CHART_VERSION=${RELEASE} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL} APP_VERSION=${VOYAGERMESH_HAPROXY_INGRESS_TAG}",
 						"make chart-voyager-crds CHART_VERSION=${RELEASE} CHART_REGISTRY=${CHART_REGISTRY} CHART_REGISTRY_URL=${CHART_REGISTRY_URL} APP_VERSION=${VOYAGERMESH_HAPROXY_INGRESS_TAG}",
+						UpdateChartAnnotationsCmd(),
 						"./hack/scripts/update-catalog.sh",
 					},
 				},
@@ -123,14 +124,16 @@ func CreateVoyagerReleaseFile() api.Release {
 				"github.com/voyagermesh/website": api.Project{
 					Tag:           github.String(releaseNumber),
 					ReleaseBranch: "master",
-					Commands: lib.AppendIf(
-						[]string{
-							"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
-							"make docs",
+					Commands: []string{
+						"make set-assets-repo ASSETS_REPO_URL=https://github.com/appscode/static-assets",
+						"make docs",
+					},
+					Steps: []api.Step{
+						{
+							Run:  "make set-version VERSION=${TAG}",
+							When: "!hideDocs && public",
 						},
-						!hideDocs && semvers.IsPublicRelease(releaseNumber),
-						"make set-version VERSION=${TAG}",
-					),
+					},
 					Changelog: api.SkipChangelog,
 				},
 			},
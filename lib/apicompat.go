@@ -0,0 +1,175 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"github.com/Masterminds/semver/v3"
+	"golang.org/x/mod/modfile"
+	shell "gomodules.xyz/go-sh"
+)
+
+type GoModule struct {
+	Dir  string
+	Path string
+}
+
+// GoModules lists the Go modules of the repo in dir. Hidden, vendor and
+// testdata directories are skipped.
+func GoModules(dir string) ([]GoModule, error) {
+	var out []GoModule
+	err := filepath.WalkDir(dir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "go.mod" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		mod, err := modfile.ParseLax(path, data, nil)
+		if err != nil {
+			return err
+		}
+		if mod.Module == nil {
+			return nil
+		}
+		out = append(out, GoModule{Dir: filepath.Dir(path), Path: mod.Module.Mod.Path})
+		return nil
+	})
+	return out, err
+}
+
+// ParseGoreleaseReport returns the incompatible changes listed in a gorelease
+// report, prefixed with their package. A report looks like:
+//
+//	# kubedb.dev/apimachinery/apis/kubedb/v1
+//	## incompatible changes
+//	(*Postgres).SetDefaults: changed from func() to func(*Topology)
+//	## compatible changes
+//	PostgresSpec.Arbiter: added
+//
+//	# summary
+//	Suggested version: v0.50.0
+func ParseGoreleaseReport(report string) []string {
+	var out []string
+	var pkg string
+	var incompatible bool
+	scanner := bufio.NewScanner(strings.NewReader(report))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "## "):
+			incompatible = line == "## incompatible changes"
+		case strings.HasPrefix(line, "# "):
+			pkg = strings.TrimPrefix(line, "# ")
+			incompatible = false
+		case incompatible && pkg != "summary":
+			out = append(out, pkg+": "+line)
+		}
+	}
+	return out
+}
+
+// ModuleTag returns the tag of version for the Go module m of the repo at dir.
+// Modules in a subdirectory are tagged with the directory as prefix, eg,
+// client/v1.2.3.
+func ModuleTag(dir string, m GoModule, version string) string {
+	rel, err := filepath.Rel(dir, m.Dir)
+	if err != nil || rel == "." {
+		return version
+	}
+	return filepath.ToSlash(rel) + "/" + version
+}
+
+// CheckAPICompatibility runs gorelease in every Go module of the repo at dir
+// and returns the modules with incompatible API changes since base. Modules
+// without a base tag are skipped. gorelease must be installed in PATH. The
+// returned error lists the modules gorelease failed to check, so it does not
+// mean incompatible changes.
+func CheckAPICompatibility(sh *shell.Session, dir, base string) ([]api.ModuleAPIChanges, error) {
+	if _, err := exec.LookPath("gorelease"); err != nil {
+		return nil, fmt.Errorf("gorelease not found in PATH")
+	}
+	modules, err := GoModules(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// pushd, popd
+	wdOrig := sh.Getwd()
+	defer sh.SetDir(wdOrig)
+
+	var out []api.ModuleAPIChanges
+	var errs []error
+	for _, m := range modules {
+		sh.SetDir(m.Dir)
+		if tag := ModuleTag(dir, m, base); tag != base {
+			// gorelease resolves the base version of a nested module from its prefixed tag
+			if sh.Command("git", "rev-parse", "-q", "--verify", "refs/tags/"+tag).Run() != nil {
+				fmt.Printf("skipping API compatibility of module %s, tag %s not found\n", m.Path, tag)
+				continue
+			}
+		}
+		data, err := sh.Command("gorelease", "-base="+base).Output()
+		report := string(data)
+		if err != nil && !strings.Contains(report, "# summary") {
+			errs = append(errs, fmt.Errorf("gorelease failed for module %s: %v", m.Path, err))
+			continue
+		}
+		if changes := ParseGoreleaseReport(report); len(changes) > 0 {
+			out = append(out, api.ModuleAPIChanges{
+				Module:       m.Path,
+				Incompatible: changes,
+			})
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// BreaksAPICompatibility reports whether tagging vTag after base would ship
+// incompatible API changes in a minor or patch release of a v1+ module.
+func BreaksAPICompatibility(base string, vTag *semver.Version, changes []api.ModuleAPIChanges) bool {
+	if len(changes) == 0 || base == "" || vTag.Major() == 0 {
+		return false
+	}
+	vBase, err := semver.NewVersion(base)
+	if err != nil {
+		return false
+	}
+	return vBase.Major() == vTag.Major()
+}
@@ -0,0 +1,90 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const goreleaseReport = `# kubedb.dev/apimachinery/apis/kubedb/v1
+## incompatible changes
+(*Postgres).SetDefaults: changed from func() to func(*Topology)
+PostgresSpec.Init: removed
+## compatible changes
+PostgresSpec.Arbiter: added
+
+# kubedb.dev/apimachinery/client
+## compatible changes
+NewClient: added
+
+# summary
+Inferred base version: v0.49.0
+Suggested version: v0.50.0
+`
+
+func TestParseGoreleaseReport(t *testing.T) {
+	want := []string{
+		"kubedb.dev/apimachinery/apis/kubedb/v1: (*Postgres).SetDefaults: changed from func() to func(*Topology)",
+		"kubedb.dev/apimachinery/apis/kubedb/v1: PostgresSpec.Init: removed",
+	}
+	if got := ParseGoreleaseReport(goreleaseReport); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseGoreleaseReport() = %v, want %v", got, want)
+	}
+}
+
+func TestBreaksAPICompatibility(t *testing.T) {
+	changes := []api.ModuleAPIChanges{{Module: "kmodules.xyz/client-go", Incompatible: []string{"Foo: removed"}}}
+	tests := []struct {
+		base    string
+		tag     string
+		changes []api.ModuleAPIChanges
+		want    bool
+	}{
+		{"v1.2.0", "v1.3.0", changes, true},
+		{"v1.2.0", "v1.2.1", changes, true},
+		{"v1.2.0", "v2.0.0", changes, false},
+		{"v0.49.0", "v0.50.0", changes, false},
+		{"v1.2.0", "v1.3.0", nil, false},
+		{"", "v1.0.0", changes, false},
+	}
+	for _, tt := range tests {
+		if got := BreaksAPICompatibility(tt.base, semver.MustParse(tt.tag), tt.changes); got != tt.want {
+			t.Errorf("BreaksAPICompatibility(%q, %q) = %v, want %v", tt.base, tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestModuleTag(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"/src/repo", "v1.2.3"},
+		{"/src/repo/client", "client/v1.2.3"},
+		{"/src/repo/apis/core", "apis/core/v1.2.3"},
+	}
+	for _, tt := range tests {
+		if got := ModuleTag("/src/repo", GoModule{Dir: tt.dir}, "v1.2.3"); got != tt.want {
+			t.Errorf("ModuleTag(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"fmt"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"gomodules.xyz/sets"
+)
+
+// ref: https://artifacthub.io/docs/topics/annotations/helm/
+const (
+	AnnotationArtifactHubChanges    = "artifacthub.io/changes"
+	AnnotationArtifactHubPrerelease = "artifacthub.io/prerelease"
+)
+
+type ArtifactHubChange struct {
+	Kind        string            `json:"kind"`
+	Description string            `json:"description"`
+	Links       []ArtifactHubLink `json:"links,omitempty"`
+}
+
+type ArtifactHubLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// artifactHubKind maps the category of a commit to one of the change kinds
+// supported by Artifact Hub: added, changed, deprecated, removed, fixed and
+// security.
+func artifactHubKind(c api.Commit) string {
+	if c.Security {
+		return "security"
+	}
+	switch c.Category {
+	case api.CategoryFeature:
+		return "added"
+	case api.CategoryBugFix:
+		return "fixed"
+	default:
+		return "changed"
+	}
+}
+
+// ArtifactHubChanges returns the artifacthub.io/changes entries of the
+// commits of a repo. Filtered commits and repeated subjects are skipped.
+func ArtifactHubChanges(repoURL string, commits []api.Commit) []ArtifactHubChange {
+	var out []ArtifactHubChange
+	seen := sets.NewString()
+	for _, c := range commits {
+		if c.Filtered || seen.Has(c.Subject) {
+			continue
+		}
+		seen.Insert(c.Subject)
+
+		change := ArtifactHubChange{
+			Kind:        artifactHubKind(c),
+			Description: c.Subject,
+		}
+		if c.PRURL != "" {
+			change.Links = append(change.Links, ArtifactHubLink{
+				Name: fmt.Sprintf("GitHub PR #%d", c.PR),
+				URL:  c.PRURL,
+			})
+		}
+		for _, id := range c.Advisories {
+			change.Links = append(change.Links, ArtifactHubLink{
+				Name: id,
+				URL:  AdvisoryURL(id),
+			})
+		}
+		if len(change.Links) == 0 && c.SHA != "" {
+			change.Links = append(change.Links, ArtifactHubLink{
+				Name: "GitHub commit",
+				URL:  fmt.Sprintf("https://%s/commit/%s", repoURL, c.SHA),
+			})
+		}
+		out = append(out, change)
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+func TestArtifactHubChanges(t *testing.T) {
+	commits := []api.Commit{
+		{SHA: "aaa", Subject: "Add TLS support", Category: api.CategoryFeature, PR: 12, PRURL: "https://github.com/kubedb/mongodb/pull/12"},
+		{SHA: "bbb", Subject: "Fix panic", Category: api.CategoryBugFix},
+		{SHA: "ccc", Subject: "Update deps", Category: api.CategoryDependencies},
+		{SHA: "ddd", Subject: "Escape user input", Category: api.CategoryBugFix, Security: true, Advisories: []string{"CVE-2022-1234"}},
+		{SHA: "eee", Subject: "Prepare for release", Filtered: true},
+		{SHA: "fff", Subject: "Fix panic", Category: api.CategoryBugFix},
+	}
+	want := []ArtifactHubChange{
+		{Kind: "added", Description: "Add TLS support", Links: []ArtifactHubLink{{Name: "GitHub PR #12", URL: "https://github.com/kubedb/mongodb/pull/12"}}},
+		{Kind: "fixed", Description: "Fix panic", Links: []ArtifactHubLink{{Name: "GitHub commit", URL: "https://github.com/kubedb/mongodb/commit/bbb"}}},
+		{Kind: "changed", Description: "Update deps", Links: []ArtifactHubLink{{Name: "GitHub commit", URL: "https://github.com/kubedb/mongodb/commit/ccc"}}},
+		{Kind: "security", Description: "Escape user input", Links: []ArtifactHubLink{{Name: "CVE-2022-1234", URL: AdvisoryURL("CVE-2022-1234")}}},
+	}
+	got := ArtifactHubChanges("github.com/kubedb/mongodb", commits)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ArtifactHubChanges() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"github.com/google/go-github/v45/github"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var (
+	conventionalCommitRegex = regexp.MustCompile(`^(\w+)(\(([^)]*)\))?(!)?:\s`)
+	prSuffixRegex           = regexp.MustCompile(`\(#(\d+)\)\s*$`)
+	depsSubjectRegex        = regexp.MustCompile(`(?i)^(update|upgrade|bump) (deps|dependencies|dependency|go\.mod|module|modules|vendor)\b|^bump \S+ from \S+ to \S+`)
+)
+
+// prefix -> category, ref: https://www.conventionalcommits.org
+var conventionalCategories = map[string]api.CommitCategory{
+	"feat":     api.CategoryFeature,
+	"feature":  api.CategoryFeature,
+	"fix":      api.CategoryBugFix,
+	"bugfix":   api.CategoryBugFix,
+	"deps":     api.CategoryDependencies,
+	"chore":    api.CategoryChore,
+	"build":    api.CategoryChore,
+	"ci":       api.CategoryChore,
+	"docs":     api.CategoryChore,
+	"perf":     api.CategoryChore,
+	"refactor": api.CategoryChore,
+	"revert":   api.CategoryChore,
+	"style":    api.CategoryChore,
+	"test":     api.CategoryChore,
+}
+
+// label -> category, checked in order
+var labelCategories = []struct {
+	labels   []string
+	category api.CommitCategory
+}{
+	{[]string{"breaking-change", "breaking", "kind/breaking"}, api.CategoryBreaking},
+	{[]string{"bug", "kind/bug", "fix"}, api.CategoryBugFix},
+	{[]string{"enhancement", "feature", "kind/feature"}, api.CategoryFeature},
+	{[]string{"dependencies", "deps", "kind/dependencies"}, api.CategoryDependencies},
+	{[]string{"chore", "kind/cleanup", "documentation", "ci"}, api.CategoryChore},
+}
+
+// ConventionalCategory returns the category of a conventional commit subject,
+// eg, "feat(postgres): Add pgbouncer support". Scopes named deps are
+// dependency updates, eg, "chore(deps): ...".
+func ConventionalCategory(subject string) (api.CommitCategory, bool) {
+	m := conventionalCommitRegex.FindStringSubmatch(subject)
+	if m == nil {
+		return "", false
+	}
+	if m[4] == "!" {
+		return api.CategoryBreaking, true
+	}
+	if m[3] == "deps" || m[3] == "deps-dev" {
+		return api.CategoryDependencies, true
+	}
+	category, ok := conventionalCategories[strings.ToLower(m[1])]
+	return category, ok
+}
+
+// LabelCategory returns the category of a pull request from its labels.
+func LabelCategory(labels []string) (api.CommitCategory, bool) {
+	for _, lc := range labelCategories {
+		for _, l := range labels {
+			for _, want := range lc.labels {
+				if strings.EqualFold(l, want) {
+					return lc.category, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// ClassifyCommit returns the category of a commit using its conventional
+// commit prefix, then the labels of its pull request. Dependency update
+// subjects like "Update deps" are recognised too.
+func ClassifyCommit(subject string, labels []string) api.CommitCategory {
+	if category, ok := ConventionalCategory(subject); ok {
+		return category
+	}
+	if category, ok := LabelCategory(labels); ok {
+		return category
+	}
+	if depsSubjectRegex.MatchString(subject) {
+		return api.CategoryDependencies
+	}
+	return api.CategoryOther
+}
+
+// PRNumber returns the pull request number from the (#123) suffix of a
+// squash or merge commit subject.
+func PRNumber(subject string) (int, bool) {
+	m := prSuffixRegex.FindStringSubmatch(subject)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	return n, err == nil
+}
+
+// CategorizeCommits sets the labels and category of commits. Labels are
+// looked up for commits with a pull request. gh may be nil.
+func CategorizeCommits(gh *github.Client, repoURL string, commits []api.Commit) []api.Commit {
+	for idx, c := range commits {
+		n := c.PR
+		if n == 0 {
+			n, _ = PRNumber(c.Subject)
+		}
+		if n > 0 && gh != nil && len(c.Labels) == 0 {
+			pr, err := GetPullRequest(gh, repoURL, n)
+			if err != nil {
+				log.Printf("failed to get pull request %s#%d: %v", repoURL, n, err)
+			} else {
+				labels := sets.New[string]()
+				for _, label := range pr.Labels {
+					labels.Insert(label.GetName())
+				}
+				commits[idx].Labels = sets.List(labels)
+			}
+		}
+		commits[idx].Category = ClassifyCommit(c.Subject, commits[idx].Labels)
+	}
+	return commits
+}
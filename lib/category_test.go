@@ -0,0 +1,47 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"testing"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+func TestClassifyCommit(t *testing.T) {
+	tests := []struct {
+		subject string
+		labels  []string
+		want    api.CommitCategory
+	}{
+		{"feat(postgres): Add pgbouncer support (#101)", nil, api.CategoryFeature},
+		{"fix: Use correct port", []string{"enhancement"}, api.CategoryBugFix},
+		{"refactor!: Drop v1alpha1 api", nil, api.CategoryBreaking},
+		{"chore(deps): Bump golang.org/x/net", nil, api.CategoryDependencies},
+		{"docs: Fix typo", nil, api.CategoryChore},
+		{"Update deps (#1234)", nil, api.CategoryDependencies},
+		{"Bump github.com/foo/bar from 1.0.0 to 1.1.0", nil, api.CategoryDependencies},
+		{"Add support for TLS (#99)", []string{"kind/feature"}, api.CategoryFeature},
+		{"Fix panic on nil spec (#98)", []string{"bug"}, api.CategoryBugFix},
+		{"Prepare for release v0.66.0 (#97)", nil, api.CategoryOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyCommit(tt.subject, tt.labels); got != tt.want {
+			t.Errorf("ClassifyCommit(%q, %v) = %q, want %q", tt.subject, tt.labels, got, tt.want)
+		}
+	}
+}
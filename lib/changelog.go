@@ -17,22 +17,21 @@ limitations under the License.
 package lib
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	iofs "io/fs"
 	"os"
 	"path/filepath"
-	"text/template"
+	"reflect"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/appscodelabs/release-automaton/api"
-	"github.com/appscodelabs/release-automaton/templates"
 
-	"github.com/Masterminds/sprig/v3"
+	"github.com/Masterminds/semver/v3"
 )
 
-func UpdateChangelog(dir string, release api.Release, repoURL, tag string, commits []api.Commit) {
+func UpdateChangelog(dir string, release api.Release, repoURL string, rc api.ReleaseChangelog) {
 	var status api.ChangelogStatus
 	for _, projects := range release.Projects {
 		for u, project := range projects {
@@ -51,7 +50,6 @@ func UpdateChangelog(dir string, release api.Release, repoURL, tag string, commi
 		panic(err)
 	}
 
-	filenameChlog := filepath.Join(dir, "CHANGELOG.json")
 	chlog := LoadChangelog(dir, release)
 
 	var repoFound bool
@@ -61,44 +59,150 @@ func UpdateChangelog(dir string, release api.Release, repoURL, tag string, commi
 
 			var tagFound bool
 			for tagIdx := range chlog.Projects[repoIdx].Releases {
-				if chlog.Projects[repoIdx].Releases[tagIdx].Tag == tag {
-					chlog.Projects[repoIdx].Releases[tagIdx].Commits = commits
+				if chlog.Projects[repoIdx].Releases[tagIdx].Tag == rc.Tag {
+					chlog.Projects[repoIdx].Releases[tagIdx] = rc
 					tagFound = true
 					break
 				}
 			}
 			if !tagFound {
-				chlog.Projects[repoIdx].Releases = append(chlog.Projects[repoIdx].Releases, api.ReleaseChangelog{
-					Tag:     tag,
-					Commits: commits,
-				})
+				chlog.Projects[repoIdx].Releases = append(chlog.Projects[repoIdx].Releases, rc)
 			}
 			break
 		}
 	}
 	if !repoFound {
 		chlog.Projects = append(chlog.Projects, api.ProjectChangelog{
-			URL: repoURL,
-			Releases: []api.ReleaseChangelog{
-				{
-					Tag:     tag,
-					Commits: commits,
-				},
-			},
+			URL:      repoURL,
+			Releases: []api.ReleaseChangelog{rc},
 		})
 	}
+	writeChangelog(dir, chlog)
+}
+
+// UpdateChangelogNotes sets the notes of the changelog in dir to the /note
+// replies in the release tracker. Repos without a release entry yet are
+// added without releases. The changelog is only written if a note changed.
+func UpdateChangelogNotes(dir string, release api.Release, notes []api.NoteReplyData) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		panic(err)
+	}
+
+	chlog := LoadChangelog(dir, release)
+	existing := changelogNotes(chlog)
+	chlog.Notes = nil
+	for idx := range chlog.Projects {
+		chlog.Projects[idx].Notes = nil
+	}
+	for _, note := range notes {
+		if note.Repo == api.NoteAllRepos {
+			chlog.Notes = append(chlog.Notes, note.Text)
+			continue
+		}
+
+		var repoFound bool
+		for idx := range chlog.Projects {
+			if chlog.Projects[idx].URL == note.Repo {
+				chlog.Projects[idx].Notes = append(chlog.Projects[idx].Notes, note.Text)
+				repoFound = true
+				break
+			}
+		}
+		if !repoFound {
+			chlog.Projects = append(chlog.Projects, api.ProjectChangelog{
+				URL:      note.Repo,
+				Notes:    []string{note.Text},
+				Releases: []api.ReleaseChangelog{},
+			})
+		}
+	}
+	if reflect.DeepEqual(existing, changelogNotes(chlog)) {
+		return
+	}
+
+	// drop repos added for notes that were removed since
+	projects := chlog.Projects[:0]
+	for _, p := range chlog.Projects {
+		if len(p.Releases) > 0 || len(p.Notes) > 0 {
+			projects = append(projects, p)
+		}
+	}
+	chlog.Projects = projects
+	writeChangelog(dir, chlog)
+}
+
+// changelogNotes returns the notes of chlog keyed by repo.
+func changelogNotes(chlog api.Changelog) map[string][]string {
+	out := map[string][]string{}
+	if len(chlog.Notes) > 0 {
+		out[api.NoteAllRepos] = chlog.Notes
+	}
+	for _, p := range chlog.Projects {
+		if len(p.Notes) > 0 {
+			out[p.URL] = p.Notes
+		}
+	}
+	return out
+}
+
+func writeChangelog(dir string, chlog api.Changelog) {
 	chlog.Sort()
 
 	data, err := MarshalJson(chlog)
 	if err != nil {
 		panic(err)
 	}
-	err = os.WriteFile(filenameChlog, data, 0o644)
+	err = os.WriteFile(filepath.Join(dir, "CHANGELOG.json"), data, 0o644)
 	if err != nil {
 		panic(err)
 	}
 
-	WriteChangelogMarkdown(filepath.Join(dir, "README.md"), "changelog.tpl", chlog)
+	WriteChangelogMarkdown(filepath.Join(dir, "README.md"), "changelog.tpl", PrepareChangelog(filepath.Dir(dir), chlog))
+
+	err = WriteAdvisories(dir, chlog)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// PrepareChangelog credits the contributors of chlog and collapses the
+// changes common to many repos for rendering. The changelogs of older
+// releases in root are used to find first-time contributors.
+func PrepareChangelog(root string, chlog api.Changelog) api.Changelog {
+	return chlog.WithContributors(earlierChangelogs(root, chlog.Release)).Collapse()
+}
+
+// changelogCache keeps the changelogs of the releases before a release for
+// the current run, since they don't change while the release is running.
+var changelogCache = struct {
+	sync.Mutex
+	changelogs map[string][]api.Changelog // root/release -> changelogs
+}{changelogs: map[string][]api.Changelog{}}
+
+func earlierChangelogs(root, release string) []api.Changelog {
+	key := filepath.Join(root, release)
+
+	changelogCache.Lock()
+	defer changelogCache.Unlock()
+
+	if earlier, ok := changelogCache.changelogs[key]; ok {
+		return earlier
+	}
+	all, err := LoadAllChangelogs(root)
+	if err != nil && !os.IsNotExist(err) {
+		panic(err)
+	}
+	var earlier []api.Changelog
+	if vRelease, err := semver.NewVersion(release); err == nil {
+		for _, e := range all {
+			if v, err := semver.NewVersion(e.Release); err == nil && v.LessThan(vRelease) {
+				earlier = append(earlier, e)
+			}
+		}
+	}
+	changelogCache.changelogs[key] = earlier
+	return earlier
 }
 
 func LoadChangelog(dir string, release api.Release) api.Changelog {
@@ -116,49 +220,135 @@ func LoadChangelog(dir string, release api.Release) api.Changelog {
 	chlog.Release = release.Release
 	chlog.ReleaseProjectURL = fmt.Sprintf("https://github.com/%s", os.Getenv("GITHUB_REPOSITORY"))
 	chlog.DocsURL = fmt.Sprintf(release.DocsURLTemplate, release.Release)
-	chlog.ReleaseDate = time.Now().UTC()
+	setReleaseDate(&chlog, release)
 	chlog.KubernetesVersion = release.KubernetesVersion
+	chlog.CollapseThreshold = 0
+	chlog.Bots = nil
+	if release.ChangelogFilters != nil {
+		chlog.CollapseThreshold = release.ChangelogFilters.CollapseThreshold
+		chlog.Bots = release.ChangelogFilters.Bots
+	}
 
 	return chlog
 }
 
-func WriteChangelogMarkdown(filename string, tplname string, data any) {
-	var err error
-
-	err = os.MkdirAll(filepath.Dir(filename), 0o755)
+// ParseReleaseDate parses a release date like 2026-07-10 or an RFC 3339
+// timestamp.
+func ParseReleaseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
 	if err != nil {
-		panic(err)
+		return time.Time{}, fmt.Errorf("invalid release date %s, use YYYY-MM-DD or RFC 3339", s)
 	}
+	return t.UTC(), nil
+}
 
-	tpl := template.New("").Funcs(sprig.TxtFuncMap())
-	err = iofs.WalkDir(templates.FS(), ".", func(path string, d iofs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		data, err := iofs.ReadFile(templates.FS(), path)
-		if err != nil {
-			return err
-		}
-		tpl, err = tpl.New(d.Name()).Parse(string(data))
+// setReleaseDate sets the release date from the release file, or the
+// completion time of the release. Otherwise a recorded release date is kept,
+// since older changelogs have no completion time. New releases use the
+// current time.
+func setReleaseDate(chlog *api.Changelog, release api.Release) {
+	switch {
+	case release.ReleaseDate != "":
+		t, err := ParseReleaseDate(release.ReleaseDate)
 		if err != nil {
 			panic(err)
 		}
-		return nil
-	})
+		chlog.ReleaseDate = t
+	case !chlog.CompletedAt.IsZero():
+		chlog.ReleaseDate = chlog.CompletedAt
+	case !chlog.ReleaseDate.IsZero():
+		// keep the recorded date
+	default:
+		chlog.ReleaseDate = time.Now().UTC()
+	}
+}
+
+// UpdateChangelogTimeline records the release tracker and the times the
+// release was started and completed in the changelog in dir. Recorded times
+// are never changed. Zero times are ignored. The changelog is only written
+// if something changed, which is reported back.
+func UpdateChangelogTimeline(dir string, release api.Release, tracker string, startedAt, completedAt time.Time) bool {
+	err := os.MkdirAll(dir, 0o755)
 	if err != nil {
 		panic(err)
 	}
 
-	var buf bytes.Buffer
-	err = tpl.ExecuteTemplate(&buf, tplname, data)
+	chlog := LoadChangelog(dir, release)
+	var changed bool
+	if chlog.StartedAt.IsZero() && !startedAt.IsZero() {
+		chlog.StartedAt = startedAt.UTC()
+		changed = true
+	}
+	if chlog.CompletedAt.IsZero() && !completedAt.IsZero() {
+		chlog.CompletedAt = completedAt.UTC()
+		changed = true
+	}
+	if tracker != "" && chlog.ReleaseTracker != tracker {
+		chlog.ReleaseTracker = tracker
+		changed = true
+	}
+	if !changed {
+		return false
+	}
+	setReleaseDate(&chlog, release)
+	writeChangelog(dir, chlog)
+	return true
+}
+
+func WriteChangelogMarkdown(filename string, tplname string, data any) {
+	err := os.MkdirAll(filepath.Dir(filename), 0o755)
+	if err != nil {
+		panic(err)
+	}
+
+	buf, err := RenderTemplate(tplname, data)
 	if err != nil {
 		panic(err)
 	}
-	err = os.WriteFile(filename, buf.Bytes(), 0o644)
+	err = os.WriteFile(filename, buf, 0o644)
 	if err != nil {
 		panic(err)
 	}
 }
+
+// LoadAllChangelogs reads the CHANGELOG.json of every release in root,
+// sorted by release number, newest first.
+func LoadAllChangelogs(root string) ([]api.Changelog, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []api.Changelog
+	for _, fi := range entries {
+		if !fi.IsDir() {
+			continue
+		}
+		filename := filepath.Join(root, fi.Name(), "CHANGELOG.json")
+		if !Exists(filename) {
+			continue
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		var chlog api.Changelog
+		err = json.Unmarshal(data, &chlog)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", filename, err)
+		}
+		out = append(out, chlog)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		vi, errI := semver.NewVersion(out[i].Release)
+		vj, errJ := semver.NewVersion(out[j].Release)
+		if errI != nil || errJ != nil {
+			return out[i].Release > out[j].Release
+		}
+		return vi.GreaterThan(vj)
+	})
+	return out, nil
+}
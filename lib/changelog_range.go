@@ -0,0 +1,123 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"github.com/Masterminds/semver/v3"
+	"gomodules.xyz/sets"
+)
+
+// ChangelogBetween merges the changelogs of the product releases after from
+// up to and including to. Commits listed by more than one release of a
+// project are kept once.
+func ChangelogBetween(changelogs []api.Changelog, from, to string) (*api.ChangelogRange, error) {
+	vFrom, err := semver.NewVersion(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release %s: %v", from, err)
+	}
+	vTo, err := semver.NewVersion(to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release %s: %v", to, err)
+	}
+	if !vFrom.LessThan(vTo) {
+		return nil, fmt.Errorf("release %s must be older than %s", from, to)
+	}
+
+	type entry struct {
+		v     *semver.Version
+		chlog api.Changelog
+	}
+	entries := make([]entry, 0, len(changelogs))
+	for _, chlog := range changelogs {
+		v, err := semver.NewVersion(chlog.Release)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{v: v, chlog: chlog})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].v.LessThan(entries[j].v) })
+
+	out := api.ChangelogRange{
+		From: from,
+		To:   to,
+	}
+	shipped := map[string]string{} // repo url -> last tag up to from
+	projects := map[string]*api.ProjectRange{}
+	seen := map[string]sets.String{} // repo url -> commit SHAs
+	var foundTo bool
+	for _, e := range entries {
+		if vTo.LessThan(e.v) {
+			break
+		}
+		if !vFrom.LessThan(e.v) {
+			for _, p := range e.chlog.Projects {
+				if n := len(p.Releases); n > 0 {
+					shipped[p.URL] = p.Releases[n-1].Tag
+				}
+			}
+			continue
+		}
+
+		foundTo = foundTo || e.v.Equal(vTo)
+		out.ProductLine = e.chlog.ProductLine
+		out.Releases = append(out.Releases, e.chlog.Release)
+		e.chlog.Sort()
+		for _, p := range e.chlog.Projects {
+			if len(p.Releases) == 0 {
+				continue
+			}
+			pr, ok := projects[p.URL]
+			if !ok {
+				pr = &api.ProjectRange{
+					URL:  p.URL,
+					From: shipped[p.URL],
+				}
+				if pr.From == "" {
+					pr.From = p.Releases[0].Base
+				}
+				projects[p.URL] = pr
+				seen[p.URL] = sets.NewString()
+			}
+			for _, r := range p.Releases {
+				pr.To = r.Tag
+				for _, c := range r.Commits {
+					if seen[p.URL].Has(c.SHA) {
+						continue
+					}
+					seen[p.URL].Insert(c.SHA)
+					pr.Commits = append(pr.Commits, c)
+					if !c.Filtered && (c.Category == api.CategoryBreaking || c.ActionRequired) {
+						pr.Breaking = true
+					}
+				}
+			}
+		}
+	}
+	if !foundTo {
+		return nil, fmt.Errorf("no changelog found for release %s", to)
+	}
+
+	for _, u := range Keys(projects) {
+		out.Projects = append(out.Projects, *projects[u])
+	}
+	return &out, nil
+}
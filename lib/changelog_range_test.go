@@ -0,0 +1,77 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"testing"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+func TestChangelogBetween(t *testing.T) {
+	const repo = "github.com/kubedb/operator"
+	const newRepo = "github.com/kubedb/ui-server"
+	changelogs := []api.Changelog{
+		{
+			Release: "v2026.7.10",
+			Projects: []api.ProjectChangelog{
+				{URL: repo, Releases: []api.ReleaseChangelog{{Tag: "v0.40.0", Base: "v0.39.1", Commits: []api.Commit{
+					{SHA: "c3", Subject: "Drop v1alpha1 api", Category: api.CategoryBreaking},
+					{SHA: "c2", Subject: "Fix panic"},
+				}}}},
+				{URL: newRepo, Releases: []api.ReleaseChangelog{{Tag: "v0.1.0", Commits: []api.Commit{{SHA: "u1", Subject: "Init"}}}}},
+			},
+		},
+		{
+			Release: "v2026.1.19",
+			Projects: []api.ProjectChangelog{
+				{URL: repo, Releases: []api.ReleaseChangelog{{Tag: "v0.39.1", Base: "v0.39.0", Commits: []api.Commit{{SHA: "c2", Subject: "Fix panic"}}}}},
+			},
+		},
+		{
+			Release: "v2025.10.17",
+			Projects: []api.ProjectChangelog{
+				{URL: repo, Releases: []api.ReleaseChangelog{{Tag: "v0.39.0", Commits: []api.Commit{{SHA: "c1", Subject: "Add backup"}}}}},
+			},
+		},
+	}
+
+	r, err := ChangelogBetween(changelogs, "v2025.10.17", "v2026.7.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(r.Releases); got != 2 || r.Releases[0] != "v2026.1.19" {
+		t.Errorf("Releases = %v", r.Releases)
+	}
+	if len(r.Projects) != 2 {
+		t.Fatalf("Projects = %v", r.Projects)
+	}
+	p := r.Projects[0]
+	if p.URL != repo || p.From != "v0.39.0" || p.To != "v0.40.0" || !p.Breaking {
+		t.Errorf("unexpected project range %+v", p)
+	}
+	if len(p.Commits) != 2 {
+		t.Errorf("Commits = %v, want c2 and c3 once", p.Commits)
+	}
+	if p := r.Projects[1]; p.From != "" || p.To != "v0.1.0" || p.Breaking {
+		t.Errorf("unexpected project range %+v", p)
+	}
+
+	if _, err := ChangelogBetween(changelogs, "v2025.10.17", "v2026.8.1"); err == nil {
+		t.Error("expected error for unknown release")
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+func TestUpdateChangelogTimeline(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "v2026.7.10")
+	release := api.Release{
+		ProductLine:     "KubeDB",
+		Release:         "v2026.7.10",
+		DocsURLTemplate: "https://kubedb.com/docs/%s",
+	}
+	tracker := "https://github.com/kubedb/CHANGELOG/pull/100"
+	started := time.Date(2026, 7, 8, 10, 0, 0, 0, time.UTC)
+	completed := time.Date(2026, 7, 10, 18, 30, 0, 0, time.UTC)
+
+	UpdateChangelogTimeline(dir, release, tracker, started, time.Time{})
+	chlog := LoadChangelog(dir, release)
+	if !chlog.StartedAt.Equal(started) || chlog.ReleaseTracker != tracker || !chlog.CompletedAt.IsZero() {
+		t.Fatalf("unexpected timeline %v - %v of %s", chlog.StartedAt, chlog.CompletedAt, chlog.ReleaseTracker)
+	}
+
+	UpdateChangelogTimeline(dir, release, tracker, started.Add(time.Hour), completed)
+	UpdateChangelogTimeline(dir, release, tracker, started, completed.Add(time.Hour))
+	chlog = LoadChangelog(dir, release)
+	if !chlog.StartedAt.Equal(started) || !chlog.CompletedAt.Equal(completed) {
+		t.Errorf("recorded times changed to %v - %v", chlog.StartedAt, chlog.CompletedAt)
+	}
+	if !chlog.ReleaseDate.Equal(completed) {
+		t.Errorf("ReleaseDate = %v, want %v", chlog.ReleaseDate, completed)
+	}
+
+	release.ReleaseDate = "2026-07-11"
+	chlog = LoadChangelog(dir, release)
+	if want := time.Date(2026, 7, 11, 0, 0, 0, 0, time.UTC); !chlog.ReleaseDate.Equal(want) {
+		t.Errorf("ReleaseDate = %v, want %v", chlog.ReleaseDate, want)
+	}
+}
+
+func TestUpdateChangelogNotes(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "v2026.7.10")
+	release := api.Release{
+		ProductLine:     "KubeDB",
+		Release:         "v2026.7.10",
+		DocsURLTemplate: "https://kubedb.com/docs/%s",
+	}
+	const operator, cli = "github.com/kubedb/operator", "github.com/kubedb/cli"
+	UpdateChangelog(dir, release, operator, api.ReleaseChangelog{
+		Tag:     "v0.40.0",
+		Commits: []api.Commit{{SHA: "a1", Subject: "Add TLS support"}},
+	})
+
+	read := func() api.Changelog {
+		data, err := os.ReadFile(filepath.Join(dir, "CHANGELOG.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var chlog api.Changelog
+		if err = json.Unmarshal(data, &chlog); err != nil {
+			t.Fatal(err)
+		}
+		return chlog
+	}
+	notesOf := func(chlog api.Changelog) map[string][]string {
+		out := map[string][]string{}
+		for _, p := range chlog.Projects {
+			out[p.URL] = p.Notes
+		}
+		return out
+	}
+
+	// apply
+	UpdateChangelogNotes(dir, release, []api.NoteReplyData{
+		{Repo: api.NoteAllRepos, Text: "Upgrade the CRDs first."},
+		{Repo: operator, Text: "TLS is enabled by default."},
+		{Repo: cli, Text: "The cli is deprecated."},
+	})
+	chlog := read()
+	if want := []string{"Upgrade the CRDs first."}; !reflect.DeepEqual(chlog.Notes, want) {
+		t.Errorf("Notes = %v, want %v", chlog.Notes, want)
+	}
+	want := map[string][]string{
+		operator: {"TLS is enabled by default."},
+		cli:      {"The cli is deprecated."},
+	}
+	if got := notesOf(chlog); !reflect.DeepEqual(got, want) {
+		t.Errorf("project notes = %v, want %v", got, want)
+	}
+	for _, p := range chlog.Projects {
+		if p.URL == operator && (len(p.Releases) != 1 || len(p.Releases[0].Commits) != 1) {
+			t.Errorf("releases of %s changed to %+v", operator, p.Releases)
+		}
+		if p.URL == cli && len(p.Releases) != 0 {
+			t.Errorf("unexpected releases of notes only project %s: %+v", cli, p.Releases)
+		}
+	}
+
+	// edit and remove
+	UpdateChangelogNotes(dir, release, []api.NoteReplyData{
+		{Repo: operator, Text: "TLS must be enabled."},
+	})
+	chlog = read()
+	if len(chlog.Notes) != 0 {
+		t.Errorf("Notes = %v, want none", chlog.Notes)
+	}
+	want = map[string][]string{
+		operator: {"TLS must be enabled."},
+	}
+	if got := notesOf(chlog); !reflect.DeepEqual(got, want) {
+		t.Errorf("project notes = %v, want %v", got, want)
+	}
+	if len(chlog.Projects) != 1 || len(chlog.Projects[0].Releases) != 1 {
+		t.Errorf("unexpected projects %+v", chlog.Projects)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"github.com/google/go-github/v45/github"
+)
+
+// sha, author name, author date, parents, subject and body, separated by
+// unit separators. Records end with a record separator.
+const commitLogFormat = "%H%x1f%an%x1f%aI%x1f%P%x1f%s%x1f%b%x1e"
+
+var (
+	mergePRRegex     = regexp.MustCompile(`^Merge pull request #(\d+) from `)
+	mergeBranchRegex = regexp.MustCompile(`^Merge (remote-tracking )?branch `)
+)
+
+// ParseCommitLog parses the output of git log --format=commitLogFormat.
+// Merge commits of pull requests use the pull request title as subject and
+// other merge commits are dropped.
+func ParseCommitLog(out string) []api.Commit {
+	var commits []api.Commit
+	for record := range strings.SplitSeq(out, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x1f", 6)
+		if len(fields) != 6 {
+			continue
+		}
+
+		c := api.Commit{
+			SHA:     fields[0],
+			Author:  fields[1],
+			Subject: fields[4],
+		}
+		if t, err := time.Parse(time.RFC3339, fields[2]); err == nil {
+			c.Date = t.UTC()
+		}
+
+		if len(strings.Fields(fields[3])) > 1 {
+			if m := mergePRRegex.FindStringSubmatch(c.Subject); m != nil {
+				c.PR, _ = strconv.Atoi(m[1])
+				c.Subject = firstLine(fields[5])
+				if c.Subject == "" {
+					c.Subject = fields[4]
+				}
+			} else if mergeBranchRegex.MatchString(c.Subject) {
+				continue
+			}
+		}
+		if notes := ParseReleaseNotes(fields[5]); len(notes) > 0 {
+			SetReleaseNote(&c, notes)
+		}
+		c.Advisories = ParseAdvisories(fields[4] + "\n" + fields[5])
+		if c.PR == 0 {
+			if n, ok := PRNumber(c.Subject); ok {
+				c.PR = n
+				c.Subject = strings.TrimSpace(prSuffixRegex.ReplaceAllString(c.Subject, ""))
+			}
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+func firstLine(s string) string {
+	for line := range strings.SplitSeq(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// EnrichCommits adds the GitHub login of commit authors and links commits
+// to their pull requests. Commits without a (#123) suffix are looked up
+// using the commit -> pull request api. Errors are logged and ignored.
+func EnrichCommits(gh *github.Client, repoURL, base, head string, commits []api.Commit) []api.Commit {
+	owner, repo := ParseRepoURL(repoURL)
+
+	logins := map[string]string{}
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		cmp, resp, err := gh.Repositories.CompareCommits(context.TODO(), owner, repo, base, head, opt)
+		if err != nil {
+			log.Printf("failed to compare %s...%s in %s: %v", base, head, repoURL, err)
+			break
+		}
+		for _, c := range cmp.Commits {
+			if login := c.GetAuthor().GetLogin(); login != "" {
+				logins[c.GetSHA()] = login
+			}
+		}
+		if resp.NextPage == 0 || len(cmp.Commits) == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	for idx, c := range commits {
+		if login, ok := logins[c.SHA]; ok {
+			commits[idx].AuthorLogin = login
+		}
+		if c.PR == 0 {
+			prs, _, err := gh.PullRequests.ListPullRequestsWithCommit(context.TODO(), owner, repo, c.SHA, nil)
+			if err != nil {
+				log.Printf("failed to find pull request for %s@%s: %v", repoURL, c.SHA, err)
+			}
+			for _, pr := range prs {
+				if pr.GetMergedAt().IsZero() {
+					continue
+				}
+				cachePullRequest(repoURL, pr)
+				commits[idx].PR = pr.GetNumber()
+				if commits[idx].AuthorLogin == "" {
+					commits[idx].AuthorLogin = pr.GetUser().GetLogin()
+				}
+				break
+			}
+		}
+		if commits[idx].PR > 0 {
+			commits[idx].PRURL = fmt.Sprintf("https://%s/pull/%d", repoURL, commits[idx].PR)
+		}
+	}
+	return commits
+}
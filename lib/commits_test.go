@@ -0,0 +1,44 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCommitLog(t *testing.T) {
+	records := []string{
+		"1111111111111111111111111111111111111111\x1fJane Doe\x1f2026-07-09T10:00:00+06:00\x1fa\x1fUpdate deps (#123)\x1f\n",
+		"2222222222222222222222222222222222222222\x1fJohn Doe\x1f2026-07-08T10:00:00Z\x1fa b\x1fMerge pull request #45 from kubedb/tls\x1fAdd TLS support\n\nSigned-off-by: John Doe\n",
+		"3333333333333333333333333333333333333333\x1fJohn Doe\x1f2026-07-07T10:00:00Z\x1fa b\x1fMerge branch 'master' into tls\x1f\n",
+		"4444444444444444444444444444444444444444\x1fJane Doe\x1f2026-07-06T10:00:00Z\x1fa\x1fFix typo\x1f\n",
+	}
+	commits := ParseCommitLog(strings.Join(records, "\x1e") + "\x1e\n")
+	if len(commits) != 3 {
+		t.Fatalf("expected 3 commits, got %+v", commits)
+	}
+	if c := commits[0]; c.Subject != "Update deps" || c.PR != 123 || c.Author != "Jane Doe" || c.Date.Hour() != 4 {
+		t.Errorf("unexpected commit %+v", c)
+	}
+	if c := commits[1]; c.Subject != "Add TLS support" || c.PR != 45 {
+		t.Errorf("unexpected merge commit %+v", c)
+	}
+	if c := commits[2]; c.SHA != "4444444444444444444444444444444444444444" || c.PR != 0 {
+		t.Errorf("unexpected commit %+v", c)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"gomodules.xyz/envsubst"
 	shell "gomodules.xyz/go-sh"
 )
 
@@ -61,3 +62,36 @@ func toEnvKey(key string) string {
 	key = strings.ReplaceAll(key, "-", "_")
 	return strings.ToUpper(key)
 }
+
+// ExpandVars expands the ${VAR} references in s like envsubst.EvalMap, but
+// keeps unresolved references in place and returns their names instead of
+// failing on the first one.
+func ExpandVars(s string, vars map[string]string) (string, []string, error) {
+	t, err := envsubst.Parse(s)
+	if err != nil {
+		return s, nil, err
+	}
+
+	var missing []string
+	out, err := t.Execute(func(node string, key string, args []string) (string, []string, error) {
+		v, ok := vars[key]
+		switch {
+		case ok && isDefaultNode(node):
+			return v, nil, nil
+		case ok || isDefaultNode(node):
+			return v, args, nil
+		}
+		missing = append(missing, key)
+		return "${" + key + "}", nil, nil
+	})
+	return out, missing, err
+}
+
+func isDefaultNode(node string) bool {
+	switch node {
+	case "=", ":=", ":-":
+		return true
+	default:
+		return false
+	}
+}
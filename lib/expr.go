@@ -0,0 +1,207 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// EvalCondition evaluates a `when` expression of a step. Supported syntax:
+// identifiers from vars, 'quoted' or "quoted" strings, !, &&, ||, ==, != and
+// parentheses. A value is true unless it is empty or "false".
+func EvalCondition(expr string, vars map[string]string) (bool, error) {
+	if strings.TrimSpace(expr) == "" {
+		return true, nil
+	}
+	tokens, err := tokenizeCondition(expr)
+	if err != nil {
+		return false, err
+	}
+	p := &condParser{tokens: tokens, vars: vars}
+	v, err := p.parseOr()
+	if err != nil {
+		return false, fmt.Errorf("invalid condition %q: %v", expr, err)
+	}
+	if p.pos < len(p.tokens) {
+		return false, fmt.Errorf("invalid condition %q: unexpected %q", expr, p.tokens[p.pos].text)
+	}
+	return truthy(v), nil
+}
+
+type condToken struct {
+	kind string // op, ident, string
+	text string
+}
+
+func tokenizeCondition(expr string) ([]condToken, error) {
+	var tokens []condToken
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"),
+			strings.HasPrefix(expr[i:], "=="), strings.HasPrefix(expr[i:], "!="):
+			tokens = append(tokens, condToken{kind: "op", text: expr[i : i+2]})
+			i += 2
+		case c == '!' || c == '(' || c == ')':
+			tokens = append(tokens, condToken{kind: "op", text: string(c)})
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexRune(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid condition %q: unterminated string", expr)
+			}
+			tokens = append(tokens, condToken{kind: "string", text: expr[i+1 : i+1+end]})
+			i += end + 2
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(expr) && (expr[j] == '_' || unicode.IsLetter(rune(expr[j])) || unicode.IsDigit(rune(expr[j]))) {
+				j++
+			}
+			tokens = append(tokens, condToken{kind: "ident", text: expr[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("invalid condition %q: unexpected character %q", expr, c)
+		}
+	}
+	return tokens, nil
+}
+
+type condParser struct {
+	tokens []condToken
+	pos    int
+	vars   map[string]string
+}
+
+func (p *condParser) accept(op string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == "op" && p.tokens[p.pos].text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *condParser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		left = boolString(truthy(left) || truthy(right))
+	}
+	return left, nil
+}
+
+func (p *condParser) parseAnd() (string, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return "", err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return "", err
+		}
+		left = boolString(truthy(left) && truthy(right))
+	}
+	return left, nil
+}
+
+func (p *condParser) parseUnary() (string, error) {
+	if p.accept("!") {
+		v, err := p.parseUnary()
+		if err != nil {
+			return "", err
+		}
+		return boolString(!truthy(v)), nil
+	}
+	return p.parseComparison()
+}
+
+func (p *condParser) parseComparison() (string, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case p.accept("=="):
+		right, err := p.parsePrimary()
+		if err != nil {
+			return "", err
+		}
+		return boolString(left == right), nil
+	case p.accept("!="):
+		right, err := p.parsePrimary()
+		if err != nil {
+			return "", err
+		}
+		return boolString(left != right), nil
+	}
+	return left, nil
+}
+
+func (p *condParser) parsePrimary() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("unexpected end of expression")
+	}
+	if p.accept("(") {
+		v, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if !p.accept(")") {
+			return "", fmt.Errorf("missing )")
+		}
+		return v, nil
+	}
+
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case "string":
+		return t.text, nil
+	case "ident":
+		switch t.text {
+		case "true", "false":
+			return t.text, nil
+		}
+		v, ok := p.vars[t.text]
+		if !ok {
+			return "", fmt.Errorf("unknown variable %s", t.text)
+		}
+		return v, nil
+	}
+	return "", fmt.Errorf("unexpected %q", t.text)
+}
+
+func truthy(v string) bool {
+	return v != "" && v != "false"
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
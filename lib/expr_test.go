@@ -0,0 +1,59 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"testing"
+)
+
+func TestEvalCondition(t *testing.T) {
+	vars := map[string]string{
+		"prerelease": "",
+		"public":     "true",
+		"channel":    "stable",
+		"hideDocs":   "false",
+		"tag":        "v2026.7.10",
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"", true},
+		{"!hideDocs && public", true},
+		{"prerelease", false},
+		{"channel == 'stable'", true},
+		{`channel != "stable" || tag == 'v2026.7.10'`, true},
+		{"!(public && hideDocs) && !prerelease", true},
+		{"hideDocs || prerelease != ''", false},
+	}
+	for _, tt := range tests {
+		got, err := EvalCondition(tt.expr, vars)
+		if err != nil {
+			t.Errorf("EvalCondition(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("EvalCondition(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"unknown", "public &&", "(public", "public = true"} {
+		if _, err := EvalCondition(expr, vars); err == nil {
+			t.Errorf("EvalCondition(%q) should fail", expr)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+// ChangelogURL returns the url of the README.md of a release in the
+// release tracker repo.
+func ChangelogURL(chlog api.Changelog) string {
+	return fmt.Sprintf("%s/blob/%s/%s/%s/README.md", strings.TrimSuffix(chlog.ReleaseProjectURL, "/"), api.BranchMaster, api.ReleasesDir, chlog.Release)
+}
+
+func releaseSummary(chlog api.Changelog) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s was released on %s.", chlog.ProductLine, chlog.Release, chlog.ReleaseDate.UTC().Format("2006-01-02"))
+	if chlog.KubernetesVersion != "" {
+		fmt.Fprintf(&sb, " It supports Kubernetes %s.", chlog.KubernetesVersion)
+	}
+	actionRequired, highlights := chlog.ReleaseNotes()
+	for _, n := range actionRequired {
+		fmt.Fprintf(&sb, "\n\nACTION REQUIRED: %s", n.Commit.ReleaseNote)
+	}
+	for _, n := range highlights {
+		fmt.Fprintf(&sb, "\n\n%s", n.Commit.ReleaseNote)
+	}
+	return sb.String()
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Author  atomAuthor `xml:"author"`
+	Links   []atomLink `xml:"link"`
+	Summary string     `xml:"summary"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// AtomFeed renders an Atom feed with one entry per release. feedURL is the
+// url where the feed is published.
+func AtomFeed(productLine, feedURL string, changelogs []api.Changelog) ([]byte, error) {
+	feed := atomFeed{
+		ID:    feedURL,
+		Title: productLine + " Releases",
+		Links: []atomLink{{Href: feedURL, Rel: "self"}},
+	}
+	var updated time.Time
+	for _, chlog := range changelogs {
+		if chlog.ReleaseDate.After(updated) {
+			updated = chlog.ReleaseDate
+		}
+		links := []atomLink{{Href: ChangelogURL(chlog), Rel: "alternate"}}
+		if chlog.DocsURL != "" {
+			links = append(links, atomLink{Href: chlog.DocsURL, Rel: "related"})
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      ChangelogURL(chlog),
+			Title:   chlog.ProductLine + " " + chlog.Release,
+			Updated: chlog.ReleaseDate.UTC().Format(time.RFC3339),
+			Author:  atomAuthor{Name: chlog.ProductLine},
+			Links:   links,
+			Summary: releaseSummary(chlog),
+		})
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// ref: https://www.jsonfeed.org/version/1.1/
+type jsonFeed struct {
+	Version string         `json:"version"`
+	Title   string         `json:"title"`
+	FeedURL string         `json:"feed_url,omitempty"`
+	Items   []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	ExternalURL   string `json:"external_url,omitempty"`
+	Title         string `json:"title"`
+	ContentText   string `json:"content_text"`
+	DatePublished string `json:"date_published"`
+}
+
+// JSONFeed renders a JSON Feed with one item per release.
+func JSONFeed(productLine, feedURL string, changelogs []api.Changelog) ([]byte, error) {
+	feed := jsonFeed{
+		Version: "https://jsonfeed.org/version/1.1",
+		Title:   productLine + " Releases",
+		FeedURL: feedURL,
+		Items:   []jsonFeedItem{},
+	}
+	for _, chlog := range changelogs {
+		feed.Items = append(feed.Items, jsonFeedItem{
+			ID:            ChangelogURL(chlog),
+			URL:           ChangelogURL(chlog),
+			ExternalURL:   chlog.DocsURL,
+			Title:         chlog.ProductLine + " " + chlog.Release,
+			ContentText:   releaseSummary(chlog),
+			DatePublished: chlog.ReleaseDate.UTC().Format(time.RFC3339),
+		})
+	}
+	data, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// KeepAChangelog is the data of the keep-a-changelog.tpl template.
+type KeepAChangelog struct {
+	ProductLine string
+	Releases    []KeepAChangelogRelease
+}
+
+type KeepAChangelogRelease struct {
+	api.Changelog
+	URL      string
+	Sections []KeepAChangelogSection
+}
+
+type KeepAChangelogSection struct {
+	Name    string
+	Entries []string
+}
+
+// category -> keep a changelog section, ref: https://keepachangelog.com/en/1.1.0/
+// Dependency updates and chores are left to the full changelog.
+var keepAChangelogSections = []struct {
+	name       string
+	categories []api.CommitCategory
+}{
+	{"Changed", []api.CommitCategory{api.CategoryBreaking}},
+	{"Added", []api.CommitCategory{api.CategoryFeature}},
+	{"Fixed", []api.CommitCategory{api.CategoryBugFix}},
+}
+
+// NewKeepAChangelog builds a product history in the Keep a Changelog format.
+func NewKeepAChangelog(productLine string, changelogs []api.Changelog) KeepAChangelog {
+	out := KeepAChangelog{ProductLine: productLine}
+	for _, chlog := range changelogs {
+		r := KeepAChangelogRelease{
+			Changelog: chlog,
+			URL:       ChangelogURL(chlog),
+		}
+		for _, s := range keepAChangelogSections {
+			seen := map[string]bool{}
+			var entries []string
+			for _, p := range chlog.Projects {
+				for _, rel := range p.Releases {
+					for _, c := range rel.Commits {
+						if c.Filtered || !containsCategory(s.categories, c.Category) {
+							continue
+						}
+						entry := c.Subject
+						if c.ReleaseNote != "" {
+							entry = c.ReleaseNote
+						}
+						if c.Category == api.CategoryBreaking {
+							entry = "**Breaking:** " + entry
+						}
+						entry = strings.ReplaceAll(entry, "\n", "\n  ")
+						if c.PRURL != "" {
+							entry += fmt.Sprintf(" ([%s#%d](%s))", strings.TrimPrefix(p.URL, "github.com/"), c.PR, c.PRURL)
+						} else {
+							entry += fmt.Sprintf(" (%s)", strings.TrimPrefix(p.URL, "github.com/"))
+						}
+						if !seen[entry] {
+							seen[entry] = true
+							entries = append(entries, entry)
+						}
+					}
+				}
+			}
+			if len(entries) > 0 {
+				r.Sections = append(r.Sections, KeepAChangelogSection{Name: s.name, Entries: entries})
+			}
+		}
+		out.Releases = append(out.Releases, r)
+	}
+	return out
+}
+
+func containsCategory(categories []api.CommitCategory, c api.CommitCategory) bool {
+	for _, x := range categories {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
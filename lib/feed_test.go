@@ -0,0 +1,116 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+func TestFeeds(t *testing.T) {
+	changelogs := []api.Changelog{
+		{
+			ProductLine:       "KubeDB",
+			Release:           "v2026.7.10",
+			ReleaseProjectURL: "https://github.com/kubedb/CHANGELOG",
+			DocsURL:           "https://kubedb.com/docs/v2026.7.10",
+			ReleaseDate:       time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC),
+			KubernetesVersion: "1.33",
+			Projects: []api.ProjectChangelog{
+				{URL: "github.com/kubedb/operator", Releases: []api.ReleaseChangelog{{Tag: "v0.40.0", Commits: []api.Commit{
+					{Subject: "Add TLS support", Category: api.CategoryFeature, ReleaseNote: "TLS is supported", PR: 12, PRURL: "https://github.com/kubedb/operator/pull/12"},
+					{Subject: "Drop v1alpha1 api", Category: api.CategoryBreaking, ReleaseNote: "v1alpha1 is removed", ActionRequired: true},
+					{Subject: "Fix panic on nil spec", Category: api.CategoryBugFix},
+					{Subject: "Update deps", Category: api.CategoryDependencies},
+					{Subject: "Fix flaky e2e test", Category: api.CategoryBugFix, Filtered: true},
+				}}}},
+			},
+		},
+		{
+			ProductLine:       "KubeDB",
+			Release:           "v2026.1.19",
+			ReleaseProjectURL: "https://github.com/kubedb/CHANGELOG",
+			ReleaseDate:       time.Date(2026, 1, 19, 12, 0, 0, 0, time.UTC),
+		},
+	}
+	const feedURL = "https://kubedb.com/feed"
+
+	tests := []struct {
+		name   string
+		render func() ([]byte, error)
+		want   []string
+	}{
+		{
+			name:   "atom",
+			render: func() ([]byte, error) { return AtomFeed("KubeDB", feedURL, changelogs) },
+			want: []string{
+				`<feed xmlns="http://www.w3.org/2005/Atom">`,
+				`<updated>2026-07-10T12:00:00Z</updated>`,
+				`<id>https://github.com/kubedb/CHANGELOG/blob/master/releases/v2026.7.10/README.md</id>`,
+				`<link href="https://kubedb.com/docs/v2026.7.10" rel="related"></link>`,
+				`<title>KubeDB v2026.1.19</title>`,
+				`It supports Kubernetes 1.33.`,
+				`ACTION REQUIRED: v1alpha1 is removed`,
+			},
+		},
+		{
+			name:   "json",
+			render: func() ([]byte, error) { return JSONFeed("KubeDB", feedURL, changelogs) },
+			want: []string{
+				`"version": "https://jsonfeed.org/version/1.1"`,
+				`"feed_url": "https://kubedb.com/feed"`,
+				`"external_url": "https://kubedb.com/docs/v2026.7.10"`,
+				`"date_published": "2026-01-19T12:00:00Z"`,
+				`KubeDB v2026.7.10 was released on 2026-07-10.`,
+			},
+		},
+		{
+			name: "keep-a-changelog",
+			render: func() ([]byte, error) {
+				return []byte(fmt.Sprintf("%+v", NewKeepAChangelog("KubeDB", changelogs).Releases[0].Sections)), nil
+			},
+			want: []string{
+				`{Name:Changed Entries:[**Breaking:** v1alpha1 is removed (kubedb/operator)]}`,
+				`{Name:Added Entries:[TLS is supported ([kubedb/operator#12](https://github.com/kubedb/operator/pull/12))]}`,
+				`{Name:Fixed Entries:[Fix panic on nil spec (kubedb/operator)]}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.render()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("missing %q in\n%s", want, data)
+				}
+			}
+			if strings.Contains(string(data), "Update deps") {
+				t.Errorf("dependency updates must be left out\n%s", data)
+			}
+			if strings.Contains(string(data), "Fix flaky e2e test") {
+				t.Errorf("filtered commits must be left out\n%s", data)
+			}
+		})
+	}
+}
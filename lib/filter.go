@@ -0,0 +1,79 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+// FilterCommits marks the commits matching any of the filters as filtered.
+func FilterCommits(filters *api.ChangelogFilters, commits []api.Commit) ([]api.Commit, error) {
+	if filters == nil {
+		return commits, nil
+	}
+
+	subjects := make([]*regexp.Regexp, 0, len(filters.Subjects))
+	for _, expr := range filters.Subjects {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid changelog subject filter %q: %v", expr, err)
+		}
+		subjects = append(subjects, re)
+	}
+
+	for idx, c := range commits {
+		commits[idx].Filtered = matchesAny(subjects, c.Subject) ||
+			containsFold(filters.Authors, c.Author) ||
+			containsFold(filters.Authors, c.AuthorLogin) ||
+			intersectsFold(filters.Labels, c.Labels)
+	}
+	return commits, nil
+}
+
+func matchesAny(exprs []*regexp.Regexp, s string) bool {
+	for _, re := range exprs {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, x := range list {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func intersectsFold(list, values []string) bool {
+	for _, v := range values {
+		if containsFold(list, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"testing"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+func TestFilterCommits(t *testing.T) {
+	filters := &api.ChangelogFilters{
+		Subjects: []string{`^Prepare for release v`},
+		Authors:  []string{"dependabot[bot]"},
+		Labels:   []string{"skip-changelog"},
+	}
+	commits, err := FilterCommits(filters, []api.Commit{
+		{Subject: "Prepare for release v0.66.0"},
+		{Subject: "Bump golang.org/x/net", AuthorLogin: "dependabot[bot]"},
+		{Subject: "Fix typo", Labels: []string{"Skip-Changelog"}},
+		{Subject: "Add TLS support", Author: "Jane"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []bool{true, true, true, false} {
+		if commits[i].Filtered != want {
+			t.Errorf("commit %q filtered = %v, want %v", commits[i].Subject, commits[i].Filtered, want)
+		}
+	}
+}
+
+func TestChangelogCollapse(t *testing.T) {
+	chlog := api.Changelog{
+		CollapseThreshold: 2,
+		Projects: []api.ProjectChangelog{
+			{URL: "github.com/kubedb/cli", Releases: []api.ReleaseChangelog{{Tag: "v0.1.0", Commits: []api.Commit{{Subject: "Update deps"}, {Subject: "Add TLS"}}}}},
+			{URL: "github.com/kubedb/postgres", Releases: []api.ReleaseChangelog{{Tag: "v0.1.0", Commits: []api.Commit{{Subject: "Update deps"}}}}},
+		},
+	}
+	out := chlog.Collapse()
+	if len(out.Collapsed) != 1 || len(out.Collapsed[0].Repos) != 2 {
+		t.Fatalf("unexpected collapsed commits %+v", out.Collapsed)
+	}
+	if !out.Projects[0].Releases[0].Commits[0].Filtered || out.Projects[0].Releases[0].Commits[1].Filtered {
+		t.Errorf("unexpected commits %+v", out.Projects[0].Releases[0].Commits)
+	}
+	if chlog.Projects[0].Releases[0].Commits[0].Filtered {
+		t.Error("Collapse modified the original changelog")
+	}
+}
+
+func TestReleaseNotesSkipFiltered(t *testing.T) {
+	chlog := api.Changelog{
+		Projects: []api.ProjectChangelog{
+			{URL: "github.com/kubedb/cli", Releases: []api.ReleaseChangelog{{Tag: "v0.1.0", Commits: []api.Commit{
+				{Subject: "Add TLS", ReleaseNote: "TLS is supported"},
+				{Subject: "Prepare for release v0.1.0", ReleaseNote: "Release v0.1.0", Filtered: true},
+				{Subject: "Drop v1alpha1", ReleaseNote: "v1alpha1 is removed", ActionRequired: true, Filtered: true},
+			}}}},
+		},
+	}
+	actionRequired, highlights := chlog.ReleaseNotes()
+	if len(actionRequired) != 0 {
+		t.Errorf("unexpected action required notes %+v", actionRequired)
+	}
+	if len(highlights) != 1 || highlights[0].Commit.Subject != "Add TLS" {
+		t.Errorf("unexpected highlights %+v", highlights)
+	}
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package lib
 
 import (
-	"bytes"
+	"encoding/base64"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 
 	"github.com/appscodelabs/release-automaton/api"
@@ -52,6 +54,81 @@ func ConfigureGit(sh *shell.Session, key, value string, global bool) error {
 	return sh.Command("git", args...).Run()
 }
 
+// SetSessionGitConfig sets a git config for the later git commands of sh
+// through the GIT_CONFIG_COUNT, GIT_CONFIG_KEY_<n> and GIT_CONFIG_VALUE_<n>
+// env vars, without writing to any git config file.
+func SetSessionGitConfig(sh *shell.Session, key, value string) error {
+	count, ok := sh.Env["GIT_CONFIG_COUNT"]
+	if !ok {
+		count = os.Getenv("GIT_CONFIG_COUNT")
+	}
+	n := 0
+	if count != "" {
+		var err error
+		n, err = strconv.Atoi(count)
+		if err != nil {
+			return fmt.Errorf("invalid GIT_CONFIG_COUNT %q: %v", count, err)
+		}
+	}
+	sh.SetEnv(fmt.Sprintf("GIT_CONFIG_KEY_%d", n), key)
+	sh.SetEnv(fmt.Sprintf("GIT_CONFIG_VALUE_%d", n), value)
+	sh.SetEnv("GIT_CONFIG_COUNT", strconv.Itoa(n+1))
+	return nil
+}
+
+const GitHubExtraHeaderKey = "http.https://github.com/.extraheader"
+
+// GitHubAuthHeader returns the value of the http.extraheader git config used
+// to authenticate against the repos of owner on github.com.
+func GitHubAuthHeader(owner string) (string, error) {
+	app, err := LoadGitHubApp()
+	if err != nil {
+		return "", err
+	}
+	if app != nil {
+		token, err := app.Token(owner)
+		if err != nil {
+			return "", err
+		}
+		return basicAuthHeader("x-access-token", token), nil
+	}
+	return basicAuthHeader(os.Getenv(api.GitHubUserKey), os.Getenv(api.GitHubTokenKey)), nil
+}
+
+func basicAuthHeader(user, password string) string {
+	creds := base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+	return "AUTHORIZATION: basic " + creds
+}
+
+// ConfigureGitHubAuth stores the GitHub credentials in the local config of
+// the current repo without printing them to the session log.
+func ConfigureGitHubAuth(sh *shell.Session) error {
+	remote, err := remoteKey(sh, "origin")
+	if err != nil {
+		return err
+	}
+	owner, _ := ParseRepoURL(remote)
+	return ConfigureGitHubAuthFor(sh, sh.Getwd(), owner)
+}
+
+// ConfigureGitHubAuthFor stores the GitHub credentials of owner in the local
+// config of the repo in dir. GitHub App tokens are kept up to date in the
+// config as they are refreshed.
+func ConfigureGitHubAuthFor(sh *shell.Session, dir, owner string) error {
+	header, err := GitHubAuthHeader(owner)
+	if err != nil {
+		return err
+	}
+	if app, _ := LoadGitHubApp(); app != nil {
+		app.trackGitDir(dir, owner)
+	}
+
+	prev := sh.ShowCMD
+	sh.ShowCMD = false
+	defer func() { sh.ShowCMD = prev }()
+	return sh.Command("git", "-C", dir, "config", "--local", GitHubExtraHeaderKey, header).Run()
+}
+
 func ListTags(sh *shell.Session) ([]string, error) {
 	data, err := sh.Command("git", "tag").Output()
 	if err != nil {
@@ -61,49 +138,44 @@ func ListTags(sh *shell.Session) ([]string, error) {
 }
 
 func RemoteBranchExists(sh *shell.Session, branch string) bool {
-	data, err := sh.Command("git", "ls-remote", "--heads", "origin", branch).Output()
+	return RemoteBranchExistsIn(sh, "origin", branch)
+}
+
+// RemoteBranchExistsIn checks for a branch in the given remote name or URL,
+// so it can be used without a clone of the repo.
+func RemoteBranchExistsIn(sh *shell.Session, remote, branch string) bool {
+	refs, err := ListRemoteRefs(sh, remote)
 	if err != nil {
 		panic(err)
 	}
-	return len(bytes.TrimSpace(data)) > 0
+	_, ok := refs["refs/heads/"+branch]
+	return ok
 }
 
 func RemoteTagExists(sh *shell.Session, tag string) bool {
-	// git ls-remote --exit-code --tags origin <tag>
-	err := sh.Command("git", "ls-remote", "--exit-code", "--tags", "origin", tag).Run()
-	return err == nil
+	return GetRemoteTag(sh, tag) != ""
 }
 
 func GetRemoteTag(sh *shell.Session, tag string) string {
-	// git ls-remote --exit-code --tags origin <tag>
-	data, err := sh.Command("git", "ls-remote", "--exit-code", "--tags", "origin", tag).Output()
+	refs, err := ListRemoteRefs(sh, "origin")
 	if err != nil {
 		return ""
 	}
-	fields := strings.Fields(string(data))
-	if len(fields) == 0 {
-		return ""
-	}
-	return fields[0]
+	return refs["refs/tags/"+tag]
 }
 
 func GetRemoteCommitHash(sh *shell.Session, url, tag string) string {
-	// git ls-remote --exit-code <url> refs/tags/<tag>
 	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 		url = "https://" + url
 	}
 	if !strings.HasSuffix(url, ".git") {
 		url += ".git"
 	}
-	data, err := sh.Command("git", "ls-remote", "--exit-code", url, fmt.Sprintf("refs/tags/%s", tag)).Output()
+	refs, err := ListRemoteRefs(sh, url)
 	if err != nil {
 		return ""
 	}
-	fields := strings.Fields(string(data))
-	if len(fields) == 0 {
-		return ""
-	}
-	return fields[0]
+	return refs["refs/tags/"+tag]
 }
 
 // IsTagged checks if the current commit is tagged.
@@ -116,6 +188,27 @@ func IsTagged(sh *shell.Session) (string, bool) {
 	return strings.TrimSpace(string(data)), true
 }
 
+// TagMessage returns the message of an annotated tag in the current repo.
+func TagMessage(sh *shell.Session, tag string) (string, error) {
+	// git tag -l --format=%(contents) <tag>
+	data, err := sh.Command("git", "tag", "-l", "--format=%(contents)", tag).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// HasMessageLine checks if msg has a line equal to line, ignoring
+// surrounding white space.
+func HasMessageLine(msg, line string) bool {
+	for l := range strings.SplitSeq(msg, "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
+
 type ConditionFunc func(*shell.Session, string) bool
 
 func MeetsCondition(fn ConditionFunc, sh *shell.Session, items ...string) bool {
@@ -242,9 +335,29 @@ func PushRepo(sh *shell.Session, pushTag bool) error {
 	if pushTag {
 		args = append(args, "--tags")
 	}
+	if err := RefreshGitHubAuth(); err != nil {
+		return err
+	}
+	defer InvalidateRemoteRefs(sh, "origin")
 	return sh.Command("git", args...).Run()
 }
 
+// FetchRepo runs git fetch in the current repo. GitHub App tokens stored in
+// the git config of repos are renewed first, if they are about to expire.
+func FetchRepo(sh *shell.Session, args ...string) error {
+	if err := RefreshGitHubAuth(); err != nil {
+		return err
+	}
+	fetchArgs := make([]any, 0, len(args)+1)
+	fetchArgs = append(fetchArgs, "fetch")
+	for _, arg := range args {
+		fetchArgs = append(fetchArgs, arg)
+	}
+	return sh.Command("git", fetchArgs...).Run()
+}
+
+// TagRepo creates an annotated tag. The tag is signed if ConfigureSigning
+// was called.
 func TagRepo(sh *shell.Session, tag string, messages ...string) error {
 	args := []any{
 		"tag", "-a", tag, "-m", tag,
@@ -256,26 +369,15 @@ func TagRepo(sh *shell.Session, tag string, messages ...string) error {
 }
 
 func ListCommits(sh *shell.Session, start, end string) []api.Commit {
-	// git log --oneline --ancestry-path start..end | cat
+	// git log --first-parent --ancestry-path --format=... start..end | cat
 	// ref: https://stackoverflow.com/a/44344164/244009
-	data, err := sh.Command("git", "log", "--oneline", "--ancestry-path", fmt.Sprintf("%s..%s", start, end)).Output()
+	// --first-parent skips the commits of merged branches, the merge commit
+	// carries the pull request title instead.
+	data, err := sh.Command("git", "log", "--first-parent", "--ancestry-path", "--format="+commitLogFormat, fmt.Sprintf("%s..%s", start, end)).Output()
 	if err != nil {
 		panic(err)
 	}
-	var commits []api.Commit
-	for line := range strings.SplitSeq(string(data), "\n") {
-		if line == "" {
-			continue
-		}
-		idx := strings.IndexRune(line, ' ')
-		if idx != -1 {
-			commits = append(commits, api.Commit{
-				SHA:     line[:idx],
-				Subject: line[idx+1:],
-			})
-		}
-	}
-	return commits
+	return ParseCommitLog(string(data))
 }
 
 func ResetRepo(sh *shell.Session) error {
@@ -26,6 +26,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/appscodelabs/release-automaton/api"
@@ -43,6 +44,16 @@ const (
 )
 
 func NewGitHubClient() (*github.Client, error) {
+	app, err := LoadGitHubApp()
+	if err != nil {
+		return nil, err
+	}
+	if app != nil {
+		return github.NewClient(&http.Client{
+			Transport: &rateLimitTransport{base: &appInstallationTransport{app: app, base: http.DefaultTransport}},
+		}), nil
+	}
+
 	token, found := os.LookupEnv(api.GitHubTokenKey)
 	if !found {
 		return nil, fmt.Errorf("%s env var is not set", api.GitHubTokenKey)
@@ -87,6 +98,43 @@ func ListLabelsByIssue(ctx context.Context, gh *github.Client, owner, repo strin
 	return result, nil
 }
 
+// pullRequests caches the pull requests fetched for the changelog of the
+// current run, so that their labels, body and url cost a single request.
+var pullRequests = struct {
+	sync.Mutex
+	prs map[string]*github.PullRequest // repo url#number -> pr
+}{prs: map[string]*github.PullRequest{}}
+
+func pullRequestKey(repoURL string, number int) string {
+	return fmt.Sprintf("%s#%d", repoURL, number)
+}
+
+// cachePullRequest records a pull request of repoURL returned by a list call.
+func cachePullRequest(repoURL string, pr *github.PullRequest) {
+	pullRequests.Lock()
+	defer pullRequests.Unlock()
+	pullRequests.prs[pullRequestKey(repoURL, pr.GetNumber())] = pr
+}
+
+// GetPullRequest returns a pull request of repoURL. Each pull request is
+// fetched once per run.
+func GetPullRequest(gh *github.Client, repoURL string, number int) (*github.PullRequest, error) {
+	pullRequests.Lock()
+	defer pullRequests.Unlock()
+
+	key := pullRequestKey(repoURL, number)
+	if pr, ok := pullRequests.prs[key]; ok {
+		return pr, nil
+	}
+	owner, repo := ParseRepoURL(repoURL)
+	pr, _, err := gh.PullRequests.Get(context.TODO(), owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	pullRequests.prs[key] = pr
+	return pr, nil
+}
+
 func ListReleases(ctx context.Context, gh *github.Client, owner, repo string) ([]*github.RepositoryRelease, error) {
 	opt := &github.ListOptions{
 		PerPage: 100,
@@ -201,17 +249,25 @@ func PRApproved(gh *github.Client, owner string, repo string, prNumber int) (boo
 	if err != nil {
 		return false, err
 	}
+	states := make([]string, 0, len(reviews))
 	for _, review := range reviews {
-		if review.GetState() == "REQUEST_CHANGES" {
-			return false, nil
+		states = append(states, review.GetState())
+	}
+	return reviewsApproved(states), nil
+}
+
+func reviewsApproved(states []string) bool {
+	for _, state := range states {
+		if state == "REQUEST_CHANGES" {
+			return false
 		}
 	}
-	for _, review := range reviews {
-		if review.GetState() == "APPROVED" {
-			return true, nil
+	for _, state := range states {
+		if state == "APPROVED" {
+			return true
 		}
 	}
-	return false, nil
+	return false
 }
 
 func CreatePR(gh *github.Client, owner string, repo string, req *github.NewPullRequest, labels ...string) (*github.PullRequest, error) {
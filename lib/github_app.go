@@ -0,0 +1,302 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"github.com/google/go-github/v45/github"
+	shell "gomodules.xyz/go-sh"
+)
+
+// Installation tokens are valid for an hour. They are refreshed this long
+// before they expire.
+const installationTokenRefreshWindow = 5 * time.Minute
+
+// GitHubApp mints installation tokens of a GitHub App, one per org or user
+// that installed the app.
+// ref: https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/authenticating-as-a-github-app-installation
+type GitHubApp struct {
+	id  int64
+	key *rsa.PrivateKey
+	gh  *github.Client // authenticated as the app
+
+	mu            sync.Mutex
+	installations map[string]int64                     // owner -> installation id
+	tokens        map[string]*github.InstallationToken // owner -> token
+	gitDirs       map[string]string                    // repo dir -> owner
+}
+
+var (
+	githubAppOnce sync.Once
+	githubApp     *GitHubApp
+	githubAppErr  error
+)
+
+// LoadGitHubApp returns the GitHub App configured via environment, or nil if
+// GITHUB_APP_ID is not set.
+func LoadGitHubApp() (*GitHubApp, error) {
+	githubAppOnce.Do(func() {
+		id, found := os.LookupEnv(api.GitHubAppIDKey)
+		if !found {
+			return
+		}
+		githubApp, githubAppErr = newGitHubApp(id)
+	})
+	return githubApp, githubAppErr
+}
+
+func newGitHubApp(id string) (*GitHubApp, error) {
+	appID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", api.GitHubAppIDKey, err)
+	}
+
+	data := []byte(os.Getenv(api.GitHubAppPrivateKeyKey))
+	if len(data) == 0 {
+		filename, found := os.LookupEnv(api.GitHubAppPrivateKeyFileKey)
+		if !found {
+			return nil, fmt.Errorf("%s or %s env var is not set", api.GitHubAppPrivateKeyKey, api.GitHubAppPrivateKeyFileKey)
+		}
+		data, err = os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+	key, err := ParseRSAPrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	app := &GitHubApp{
+		id:            appID,
+		key:           key,
+		installations: map[string]int64{},
+		tokens:        map[string]*github.InstallationToken{},
+		gitDirs:       map[string]string{},
+	}
+	app.gh = github.NewClient(&http.Client{
+		Transport: &rateLimitTransport{base: &appJWTTransport{app: app, base: http.DefaultTransport}},
+	})
+	return app, nil
+}
+
+// ParseRSAPrivateKey parses a PEM encoded PKCS #1 or PKCS #8 RSA private key,
+// as downloaded from the settings of a GitHub App.
+func ParseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected RSA private key, found %T", key)
+	}
+	return rsaKey, nil
+}
+
+// JWT returns a RS256 signed JSON Web Token that authenticates as the app.
+// The clock may drift, so the token is issued a minute in the past and
+// expires in 9 minutes, below the limit of 10 minutes.
+func (a *GitHubApp) JWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(a.id, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	payload := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(payload))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return payload + "." + enc.EncodeToString(sig), nil
+}
+
+// Token returns an installation token of the app for owner. A new token is
+// minted if the cached one is about to expire, and written into the git
+// config of the repos of owner, see ConfigureGitHubAuth.
+func (a *GitHubApp) Token(owner string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if t, ok := a.tokens[owner]; ok && time.Until(t.GetExpiresAt()) > installationTokenRefreshWindow {
+		return t.GetToken(), nil
+	}
+
+	id, ok := a.installations[owner]
+	if !ok {
+		inst, resp, err := a.gh.Apps.FindOrganizationInstallation(context.TODO(), owner)
+		if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+			inst, _, err = a.gh.Apps.FindUserInstallation(context.TODO(), owner)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to find installation of GitHub App %d for %s: %v", a.id, owner, err)
+		}
+		id = inst.GetID()
+		a.installations[owner] = id
+	}
+
+	t, _, err := a.gh.Apps.CreateInstallationToken(context.TODO(), id, nil)
+	if err != nil {
+		return "", err
+	}
+	a.tokens[owner] = t
+
+	sh := shell.NewSession()
+	sh.ShowCMD = false
+	header := basicAuthHeader("x-access-token", t.GetToken())
+	for dir, o := range a.gitDirs {
+		if o != owner || !Exists(dir) {
+			continue
+		}
+		err = sh.Command("git", "-C", dir, "config", "--local", GitHubExtraHeaderKey, header).Run()
+		if err != nil {
+			return "", err
+		}
+	}
+	return t.GetToken(), nil
+}
+
+// trackGitDir records that the git config of dir holds a token of owner.
+func (a *GitHubApp) trackGitDir(dir, owner string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.gitDirs[dir] = owner
+}
+
+// refreshGitDirs renews the tokens in the git config of the repo dirs, if
+// they are about to expire.
+func (a *GitHubApp) refreshGitDirs() error {
+	a.mu.Lock()
+	owners := map[string]bool{}
+	for _, owner := range a.gitDirs {
+		owners[owner] = true
+	}
+	a.mu.Unlock()
+
+	for owner := range owners {
+		if _, err := a.Token(owner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefreshGitHubAuth renews the GitHub App tokens stored in the git config of
+// cloned repos, so that long runs can still fetch and push.
+func RefreshGitHubAuth() error {
+	app, err := LoadGitHubApp()
+	if err != nil || app == nil {
+		return err
+	}
+	return app.refreshGitDirs()
+}
+
+// appJWTTransport authenticates the requests of the app itself, eg, to mint
+// installation tokens.
+type appJWTTransport struct {
+	app  *GitHubApp
+	base http.RoundTripper
+}
+
+func (t *appJWTTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := t.app.JWT(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	return t.base.RoundTrip(req)
+}
+
+// appInstallationTransport authenticates each request with the installation
+// token of the org or user the request is about. Requests without an owner,
+// eg, /user or /graphql, use the owner of GITHUB_REPOSITORY.
+type appInstallationTransport struct {
+	app  *GitHubApp
+	base http.RoundTripper
+}
+
+func (t *appInstallationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	owner := RequestOwner(req.URL.Path)
+	if owner == "" {
+		owner, _, _ = strings.Cut(os.Getenv("GITHUB_REPOSITORY"), "/")
+	}
+	if owner == "" {
+		return nil, fmt.Errorf("can't detect the owner of %s to pick a GitHub App installation", req.URL.Path)
+	}
+	token, err := t.app.Token(owner)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+token)
+	return t.base.RoundTrip(req)
+}
+
+// RequestOwner returns the org or user of a GitHub API path, eg, kubedb for
+// /repos/kubedb/mongodb/pulls.
+func RequestOwner(p string) string {
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	if len(parts) > 3 && parts[0] == "api" && parts[1] == "v3" {
+		// GitHub Enterprise
+		parts = parts[2:]
+	}
+	if len(parts) < 2 {
+		return ""
+	}
+	switch parts[0] {
+	case "repos", "orgs", "users":
+		return parts[1]
+	}
+	return ""
+}
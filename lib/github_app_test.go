@@ -0,0 +1,152 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v45/github"
+)
+
+func TestRequestOwner(t *testing.T) {
+	tests := map[string]string{
+		"/repos/kubedb/mongodb/pulls/1":        "kubedb",
+		"/orgs/kubedb/installation":            "kubedb",
+		"/users/tamalsaha/installation":        "tamalsaha",
+		"/api/v3/repos/kubedb/mongodb/commits": "kubedb",
+		"/user":                                "",
+		"/graphql":                             "",
+	}
+	for p, want := range tests {
+		if got := RequestOwner(p); got != want {
+			t.Errorf("RequestOwner(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
+
+func newTestGitHubApp(t *testing.T) *GitHubApp {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	t.Setenv("GITHUB_APP_PRIVATE_KEY", string(data))
+	app, err := newGitHubApp("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func TestGitHubAppJWT(t *testing.T) {
+	app := newTestGitHubApp(t)
+
+	now := time.Unix(1700000000, 0)
+	jwt, err := app.JWT(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, found %d", len(parts))
+	}
+
+	enc := base64.RawURLEncoding
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&app.key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		t.Errorf("invalid signature: %v", err)
+	}
+
+	data, err := enc.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims struct {
+		IAT int64  `json:"iat"`
+		EXP int64  `json:"exp"`
+		ISS string `json:"iss"`
+	}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.ISS != "12345" || claims.IAT != now.Unix()-60 || claims.EXP != now.Unix()+540 {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+}
+
+func TestGitHubAppToken(t *testing.T) {
+	app := newTestGitHubApp(t)
+
+	var minted int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/kubedb/installation", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			t.Errorf("expected JWT auth, found %q", r.Header.Get("Authorization"))
+		}
+		fmt.Fprint(w, `{"id": 42}`)
+	})
+	mux.HandleFunc("/app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		minted++
+		expiresAt := time.Now().Add(time.Hour)
+		_ = json.NewEncoder(w).Encode(github.InstallationToken{
+			Token:     github.String(fmt.Sprintf("ghs_%d", minted)),
+			ExpiresAt: &expiresAt,
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	app.gh.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	for i := 0; i < 2; i++ {
+		token, err := app.Token("kubedb")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "ghs_1" {
+			t.Errorf("Token() = %s, want ghs_1", token)
+		}
+	}
+
+	// tokens about to expire are replaced
+	expiresAt := time.Now().Add(time.Minute)
+	app.tokens["kubedb"].ExpiresAt = &expiresAt
+	token, err := app.Token("kubedb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "ghs_2" {
+		t.Errorf("Token() = %s, want ghs_2", token)
+	}
+}
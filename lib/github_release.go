@@ -0,0 +1,98 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/google/go-github/v45/github"
+)
+
+type GitHubReleaseNotes struct {
+	ProductLine string
+	Release     string
+	// ReleaseURL links the changelog of the product release.
+	ReleaseURL string
+	Tracker    string
+	Repo       string
+	Changelog  api.ReleaseChangelog
+}
+
+// ProductReleaseURL returns the url of the changelog of a product release
+// in the repo of its release tracker.
+func ProductReleaseURL(releaseTracker, release string) string {
+	owner, repo, _ := ParsePullRequestURL(releaseTracker)
+	return fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s/%s/README.md", owner, repo, api.BranchMaster, api.ReleasesDir, release)
+}
+
+// GitHubReleaseExists checks if the GitHub release of tag exists.
+func GitHubReleaseExists(gh *github.Client, repoURL, tag string) (bool, error) {
+	owner, repo := ParseRepoURL(repoURL)
+	_, resp, err := gh.Repositories.GetReleaseByTag(context.TODO(), owner, repo, tag)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PublishGitHubRelease creates or updates the GitHub release of a tag. The
+// release is marked as a prerelease if the tag has a prerelease component.
+func PublishGitHubRelease(gh *github.Client, notes GitHubReleaseNotes) error {
+	owner, repo := ParseRepoURL(notes.Repo)
+	tag := notes.Changelog.Tag
+
+	body, err := RenderTemplate("github-release.tpl", notes)
+	if err != nil {
+		return err
+	}
+	prerelease := false
+	if v, err := semver.NewVersion(tag); err == nil {
+		prerelease = v.Prerelease() != ""
+	}
+
+	releases, err := ListReleases(context.TODO(), gh, owner, repo)
+	if err != nil {
+		return err
+	}
+	for _, r := range releases {
+		if r.GetTagName() != tag {
+			continue
+		}
+		if r.GetBody() == string(body) && r.GetPrerelease() == prerelease {
+			return nil
+		}
+		_, _, err = gh.Repositories.EditRelease(context.TODO(), owner, repo, r.GetID(), &github.RepositoryRelease{
+			Name:       github.String(tag),
+			Body:       github.String(string(body)),
+			Prerelease: github.Bool(prerelease),
+		})
+		return err
+	}
+
+	_, _, err = gh.Repositories.CreateRelease(context.TODO(), owner, repo, &github.RepositoryRelease{
+		TagName:    github.String(tag),
+		Name:       github.String(tag),
+		Body:       github.String(string(body)),
+		Prerelease: github.Bool(prerelease),
+	})
+	return err
+}
@@ -0,0 +1,383 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"github.com/google/go-github/v45/github"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// maxPullRequestsPerQuery limits the pull requests fetched by a single
+// batched GraphQL query, to stay below the node limit of GitHub.
+const maxPullRequestsPerQuery = 50
+
+// GraphQLClient queries the GitHub GraphQL API using the http client and
+// credentials of a REST client.
+// ref: https://docs.github.com/en/graphql
+type GraphQLClient struct {
+	hc  *http.Client
+	url string
+}
+
+func NewGraphQLClient(gh *github.Client) *GraphQLClient {
+	return &GraphQLClient{
+		hc:  gh.Client(),
+		url: gh.BaseURL.String() + "graphql",
+	}
+}
+
+type graphQLError struct {
+	Type    string `json:"type"`
+	Path    []any  `json:"path"`
+	Message string `json:"message"`
+}
+
+// GraphQLErrors are the errors reported for a query. The path of an error
+// starts with the alias of the field that failed.
+type GraphQLErrors []graphQLError
+
+func (e GraphQLErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Query runs a GraphQL query and decodes its data into out. If some fields
+// failed, the data of the other fields is still decoded and the errors are
+// returned as GraphQLErrors.
+func (c *GraphQLClient) Query(ctx context.Context, query string, vars map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{
+		"query":     query,
+		"variables": vars,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GraphQL query failed with status %s", resp.Status)
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return err
+	}
+	if len(result.Data) > 0 {
+		err = json.Unmarshal(result.Data, out)
+		if err != nil {
+			return err
+		}
+	}
+	if len(result.Errors) > 0 {
+		return GraphQLErrors(result.Errors)
+	}
+	return nil
+}
+
+// TrackerState is the state of the release tracker pull request needed to
+// start a run.
+type TrackerState struct {
+	State    string // open or closed
+	Draft    bool
+	Approved bool
+	Labels   sets.Set[string]
+	Comments []*github.IssueComment
+}
+
+// GetTrackerState fetches the release tracker pull request, its reviews,
+// labels and comments via the REST API. Reviews, labels and comments are
+// only fetched for open pull requests, ready for review.
+func GetTrackerState(gh *github.Client, owner, repo string, number int) (*TrackerState, error) {
+	pr, _, err := gh.PullRequests.Get(context.TODO(), owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	state := &TrackerState{
+		State: pr.GetState(),
+		Draft: pr.GetDraft(),
+	}
+	if state.Draft || state.State != "open" {
+		return state, nil
+	}
+
+	state.Approved, err = PRApproved(gh, owner, repo, number)
+	if err != nil || !state.Approved {
+		return state, err
+	}
+	state.Comments, err = ListComments(context.TODO(), gh, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	state.Labels, err = ListLabelsByIssue(context.TODO(), gh, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+const trackerStateQuery = `query($owner: String!, $repo: String!, $number: Int!, $after: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      state
+      isDraft
+      labels(first: 100) { nodes { name } }
+      reviews(first: 100) { nodes { state } }
+      comments(first: 100, after: $after) {
+        pageInfo { hasNextPage endCursor }
+        nodes { fullDatabaseId body createdAt }
+      }
+    }
+  }
+}`
+
+// GetTrackerState fetches the release tracker pull request with its reviews,
+// labels and comments in one query. Only comments beyond the first 100 need
+// more queries.
+func (c *GraphQLClient) GetTrackerState(ctx context.Context, owner, repo string, number int) (*TrackerState, error) {
+	state := &TrackerState{
+		Labels: sets.New[string](),
+	}
+	vars := map[string]any{
+		"owner":  owner,
+		"repo":   repo,
+		"number": number,
+	}
+	for page := 0; ; page++ {
+		var data struct {
+			Repository struct {
+				PullRequest struct {
+					State   string
+					IsDraft bool
+					Labels  struct {
+						Nodes []struct{ Name string }
+					}
+					Reviews struct {
+						Nodes []struct{ State string }
+					}
+					Comments struct {
+						PageInfo struct {
+							HasNextPage bool
+							EndCursor   string
+						}
+						Nodes []struct {
+							// databaseId is a 32 bit Int, too small for comment ids
+							FullDatabaseID string
+							Body           string
+							CreatedAt      time.Time
+						}
+					}
+				}
+			}
+		}
+		err := c.Query(ctx, trackerStateQuery, vars, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		pr := data.Repository.PullRequest
+		if page == 0 {
+			// MERGED is closed for the REST API
+			state.State = "closed"
+			if pr.State == "OPEN" {
+				state.State = "open"
+			}
+			state.Draft = pr.IsDraft
+			for _, l := range pr.Labels.Nodes {
+				state.Labels.Insert(l.Name)
+			}
+			reviews := make([]string, 0, len(pr.Reviews.Nodes))
+			for _, r := range pr.Reviews.Nodes {
+				reviews = append(reviews, r.State)
+			}
+			state.Approved = reviewsApproved(reviews)
+		}
+		for _, n := range pr.Comments.Nodes {
+			id, err := strconv.ParseInt(n.FullDatabaseID, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid comment id %q: %v", n.FullDatabaseID, err)
+			}
+			state.Comments = append(state.Comments, &github.IssueComment{
+				ID:        github.Int64(id),
+				Body:      github.String(n.Body),
+				CreatedAt: &n.CreatedAt,
+			})
+		}
+		if !pr.Comments.PageInfo.HasNextPage {
+			break
+		}
+		vars["after"] = pr.Comments.PageInfo.EndCursor
+	}
+	return state, nil
+}
+
+// PullRequestState is the merge and check state of a pull request.
+type PullRequestState struct {
+	State          string // OPEN, CLOSED or MERGED
+	BaseRef        string
+	Merged         bool
+	MergeCommitSHA string
+	Mergeable      string // MERGEABLE, CONFLICTING or UNKNOWN
+	// CheckStatus is the combined status of the checks of the head commit:
+	// SUCCESS, FAILURE, PENDING, ERROR or EXPECTED. Empty without checks or
+	// when fetched via the REST API.
+	CheckStatus string
+}
+
+// GetPullRequestState fetches the state of a pull request via the REST API.
+func GetPullRequestState(gh *github.Client, pr api.PullRequestReplyData) (PullRequestState, error) {
+	owner, repo := ParseRepoURL(pr.Repo)
+	p, _, err := gh.PullRequests.Get(context.TODO(), owner, repo, pr.Number)
+	if err != nil {
+		return PullRequestState{}, err
+	}
+	state := PullRequestState{
+		State:          strings.ToUpper(p.GetState()),
+		BaseRef:        p.GetBase().GetRef(),
+		Merged:         p.GetMerged(),
+		MergeCommitSHA: p.GetMergeCommitSHA(),
+		Mergeable:      "UNKNOWN",
+	}
+	if state.Merged {
+		state.State = "MERGED"
+	}
+	if p.Mergeable != nil {
+		state.Mergeable = "CONFLICTING"
+		if p.GetMergeable() {
+			state.Mergeable = "MERGEABLE"
+		}
+	}
+	return state, nil
+}
+
+const pullRequestStateFragment = `fragment prState on PullRequest {
+  state
+  baseRefName
+  merged
+  mergeCommit { oid }
+  mergeable
+  commits(last: 1) { nodes { commit { statusCheckRollup { state } } } }
+}`
+
+// GetPullRequestStates fetches the state of the pull requests in batched
+// queries, keyed by (repo, number). Pull requests that can't be fetched, eg,
+// deleted ones, are reported in the returned error map instead of failing
+// the whole batch.
+func (c *GraphQLClient) GetPullRequestStates(ctx context.Context, prs []api.PullRequestReplyData) (map[api.PullRequestReplyData]PullRequestState, map[api.PullRequestReplyData]error, error) {
+	out := make(map[api.PullRequestReplyData]PullRequestState, len(prs))
+	errs := map[api.PullRequestReplyData]error{}
+	for start := 0; start < len(prs); start += maxPullRequestsPerQuery {
+		batch := prs[start:min(start+maxPullRequestsPerQuery, len(prs))]
+
+		var data map[string]struct {
+			PullRequest *struct {
+				State       string
+				BaseRefName string
+				Merged      bool
+				MergeCommit *struct{ OID string }
+				Mergeable   string
+				Commits     struct {
+					Nodes []struct {
+						Commit struct {
+							StatusCheckRollup *struct{ State string }
+						}
+					}
+				}
+			}
+		}
+		err := c.Query(ctx, PullRequestStatesQuery(batch), nil, &data)
+		var gqlErrs GraphQLErrors
+		if err != nil && !errors.As(err, &gqlErrs) {
+			return nil, nil, err
+		}
+		aliasErrs := map[string]error{}
+		for _, e := range gqlErrs {
+			if len(e.Path) == 0 {
+				return nil, nil, err
+			}
+			aliasErrs[fmt.Sprint(e.Path[0])] = errors.New(e.Message)
+		}
+		for i, pr := range batch {
+			alias := "pr" + strconv.Itoa(i)
+			if err, ok := aliasErrs[alias]; ok {
+				errs[pr] = err
+				continue
+			}
+			result, ok := data[alias]
+			if !ok || result.PullRequest == nil {
+				errs[pr] = fmt.Errorf("pull request %s#%d not found", pr.Repo, pr.Number)
+				continue
+			}
+			p := result.PullRequest
+			state := PullRequestState{
+				State:     p.State,
+				BaseRef:   p.BaseRefName,
+				Merged:    p.Merged,
+				Mergeable: p.Mergeable,
+			}
+			if p.MergeCommit != nil {
+				state.MergeCommitSHA = p.MergeCommit.OID
+			}
+			if n := p.Commits.Nodes; len(n) > 0 && n[0].Commit.StatusCheckRollup != nil {
+				state.CheckStatus = n[0].Commit.StatusCheckRollup.State
+			}
+			out[pr] = state
+		}
+	}
+	return out, errs, nil
+}
+
+// PullRequestStatesQuery returns a query that fetches the state of all prs,
+// aliased as pr0, pr1, ...
+func PullRequestStatesQuery(prs []api.PullRequestReplyData) string {
+	var buf strings.Builder
+	buf.WriteString("query {\n")
+	for i, pr := range prs {
+		owner, repo := ParseRepoURL(pr.Repo)
+		fmt.Fprintf(&buf, "  pr%d: repository(owner: %s, name: %s) { pullRequest(number: %d) { ...prState } }\n", i, strconv.Quote(owner), strconv.Quote(repo), pr.Number)
+	}
+	buf.WriteString("}\n")
+	buf.WriteString(pullRequestStateFragment)
+	return buf.String()
+}
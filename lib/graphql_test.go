@@ -0,0 +1,160 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"github.com/google/go-github/v45/github"
+)
+
+func newTestGraphQLClient(t *testing.T, handler func(query string, vars map[string]any) string) *GraphQLClient {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprint(w, handler(req.Query, req.Variables))
+	}))
+	t.Cleanup(srv.Close)
+
+	gh := github.NewClient(nil)
+	gh.BaseURL, _ = url.Parse(srv.URL + "/")
+	return NewGraphQLClient(gh)
+}
+
+func TestGraphQLGetTrackerState(t *testing.T) {
+	var queries int
+	gql := newTestGraphQLClient(t, func(query string, vars map[string]any) string {
+		queries++
+		if vars["after"] == nil {
+			return `{"data": {"repository": {"pullRequest": {
+  "state": "OPEN", "isDraft": false,
+  "labels": {"nodes": [{"name": "locked"}]},
+  "reviews": {"nodes": [{"state": "COMMENTED"}, {"state": "APPROVED"}]},
+  "comments": {"pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+    "nodes": [{"fullDatabaseId": "1", "body": "/ok-to-release", "createdAt": "2026-07-10T10:00:00Z"}]}
+}}}}`
+		}
+		return `{"data": {"repository": {"pullRequest": {
+  "state": "OPEN",
+  "comments": {"pageInfo": {"hasNextPage": false},
+    "nodes": [{"fullDatabaseId": "3000000000", "body": "/tagged github.com/kubedb/mongodb", "createdAt": "2026-07-10T11:00:00Z"}]}
+}}}}`
+	})
+
+	state, err := gql.GetTrackerState(context.TODO(), "kubedb", "CHANGELOG", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queries != 2 {
+		t.Errorf("expected 2 queries, found %d", queries)
+	}
+	if state.State != "open" || state.Draft || !state.Approved || !state.Labels.Has(api.LabelLocked) {
+		t.Errorf("unexpected state %+v", state)
+	}
+	if len(state.Comments) != 2 || state.Comments[1].GetID() != 3000000000 || state.Comments[1].GetCreatedAt().Hour() != 11 {
+		t.Errorf("unexpected comments %+v", state.Comments)
+	}
+}
+
+func TestGraphQLGetPullRequestStates(t *testing.T) {
+	prs := []api.PullRequestReplyData{
+		{Repo: "github.com/kubedb/mongodb", Number: 12},
+		{Repo: "github.com/kubedb/redis", Number: 7},
+	}
+	gql := newTestGraphQLClient(t, func(query string, vars map[string]any) string {
+		if !strings.Contains(query, `pr1: repository(owner: "kubedb", name: "redis") { pullRequest(number: 7) { ...prState } }`) {
+			t.Errorf("unexpected query %s", query)
+		}
+		return `{"data": {
+  "pr0": {"pullRequest": {"state": "MERGED", "baseRefName": "master", "merged": true, "mergeCommit": {"oid": "abc"}, "mergeable": "UNKNOWN",
+    "commits": {"nodes": [{"commit": {"statusCheckRollup": {"state": "SUCCESS"}}}]}}},
+  "pr1": {"pullRequest": {"state": "OPEN", "merged": false, "mergeCommit": null, "mergeable": "CONFLICTING",
+    "commits": {"nodes": [{"commit": {"statusCheckRollup": null}}]}}}
+}}`
+	})
+
+	states, errs, err := gql.GetPullRequestStates(context.TODO(), prs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) > 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	want := map[api.PullRequestReplyData]PullRequestState{
+		prs[0]: {State: "MERGED", BaseRef: "master", Merged: true, MergeCommitSHA: "abc", Mergeable: "UNKNOWN", CheckStatus: "SUCCESS"},
+		prs[1]: {State: "OPEN", Mergeable: "CONFLICTING"},
+	}
+	for pr, w := range want {
+		if states[pr] != w {
+			t.Errorf("state of %s#%d = %+v, want %+v", pr.Repo, pr.Number, states[pr], w)
+		}
+	}
+}
+
+func TestGraphQLGetPullRequestStatesPartialErrors(t *testing.T) {
+	prs := []api.PullRequestReplyData{
+		{Repo: "github.com/kubedb/mongodb", Number: 12},
+		{Repo: "github.com/kubedb/redis", Number: 7},
+	}
+	gql := newTestGraphQLClient(t, func(query string, vars map[string]any) string {
+		return `{"data": {
+  "pr0": {"pullRequest": null},
+  "pr1": {"pullRequest": {"state": "OPEN", "merged": false, "mergeable": "MERGEABLE", "commits": {"nodes": []}}}
+}, "errors": [{"type": "NOT_FOUND", "path": ["pr0", "pullRequest"], "message": "Could not resolve to a PullRequest with the number of 12."}]}`
+	})
+
+	states, errs, err := gql.GetPullRequestStates(context.TODO(), prs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := errs[prs[0]]; err == nil || !strings.Contains(err.Error(), "Could not resolve") {
+		t.Errorf("expected error for %s#%d, found %v", prs[0].Repo, prs[0].Number, err)
+	}
+	if _, ok := states[prs[0]]; ok {
+		t.Errorf("unexpected state for %s#%d", prs[0].Repo, prs[0].Number)
+	}
+	if want := (PullRequestState{State: "OPEN", Mergeable: "MERGEABLE"}); states[prs[1]] != want {
+		t.Errorf("state of %s#%d = %+v, want %+v", prs[1].Repo, prs[1].Number, states[prs[1]], want)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	gql := newTestGraphQLClient(t, func(query string, vars map[string]any) string {
+		return `{"data": null, "errors": [{"type": "NOT_FOUND", "message": "Could not resolve to a PullRequest with the number of 3."}]}`
+	})
+	_, err := gql.GetTrackerState(context.TODO(), "kubedb", "CHANGELOG", 3)
+	if err == nil || !strings.Contains(err.Error(), "Could not resolve") {
+		t.Errorf("expected GraphQL error, found %v", err)
+	}
+}
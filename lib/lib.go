@@ -56,7 +56,7 @@ func HasKey(m map[string]string, key string) bool {
 	return ok
 }
 
-func Keys(m map[string]string) []string {
+func Keys[V any](m map[string]V) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
 		keys = append(keys, k)
@@ -0,0 +1,445 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"github.com/google/go-github/v45/github"
+	"github.com/hashicorp/go-getter"
+	shell "gomodules.xyz/go-sh"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	releaseFileGitPrefix = "git::"
+	releaseFilePRPrefix  = "pr::"
+)
+
+var fullSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// ReleaseFileSource describes where a release file is loaded from.
+type ReleaseFileSource struct {
+	// Kind is one of "file", "http", "git" or "pr"
+	Kind string
+	// Repo is the git repository url. Empty means the release tracker repo
+	// in the current working directory.
+	Repo string
+	// Ref is the git ref for "git" sources and the pull request url for "pr" sources.
+	Ref string
+	// Path is the file path for "file" sources, the url for "http" sources
+	// and the path inside the repository for "git" and "pr" sources.
+	Path string
+	// Checksum is an optional <type>:<value> checksum of the file content.
+	Checksum string
+}
+
+// ParseReleaseFileSource parses the --release-file flag. Supported formats are:
+//
+//	releases/v2026.7.10/release.json
+//	https://example.com/release.json?checksum=sha256:<hex>
+//	git::<ref>:releases/v2026.7.10/release.json
+//	git::https://github.com/kubedb/CHANGELOG.git//releases/v2026.7.10/release.json?ref=<ref>
+//	pr::https://github.com/kubedb/CHANGELOG/pull/123//releases/v2026.7.10/release.json
+func ParseReleaseFileSource(src string) (*ReleaseFileSource, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, fmt.Errorf("missing release file")
+	}
+
+	var checksum string
+	remote := strings.Contains(src, "://") ||
+		strings.HasPrefix(src, releaseFileGitPrefix) ||
+		strings.HasPrefix(src, releaseFilePRPrefix)
+	if idx := strings.LastIndex(src, "?"); idx != -1 && remote {
+		q, err := url.ParseQuery(src[idx+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid query in release file %s: %v", src, err)
+		}
+		checksum = q.Get("checksum")
+		q.Del("checksum")
+		src = src[:idx]
+		if len(q) > 0 {
+			src += "?" + q.Encode()
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(src, releaseFilePRPrefix):
+		prURL, p, ok := cutSubPath(strings.TrimPrefix(src, releaseFilePRPrefix))
+		if !ok || p == "" {
+			return nil, fmt.Errorf("release file %s is missing file path, expected pr::<pr-url>//<path>", src)
+		}
+		return &ReleaseFileSource{Kind: "pr", Ref: prURL, Path: p, Checksum: checksum}, nil
+	case strings.HasPrefix(src, releaseFileGitPrefix):
+		s := strings.TrimPrefix(src, releaseFileGitPrefix)
+		if !strings.Contains(s, "://") {
+			// git::<ref>:<path> inside the release tracker repo
+			ref, p, ok := strings.Cut(s, ":")
+			if !ok || ref == "" || p == "" {
+				return nil, fmt.Errorf("release file %s must be formatted as git::<ref>:<path>", src)
+			}
+			return &ReleaseFileSource{Kind: "git", Ref: ref, Path: p, Checksum: checksum}, nil
+		}
+
+		s, query, _ := strings.Cut(s, "?")
+		q, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		repo, p, ok := cutSubPath(s)
+		if !ok || p == "" || q.Get("ref") == "" {
+			return nil, fmt.Errorf("release file %s must be formatted as git::<repo-url>//<path>?ref=<ref>", src)
+		}
+		return &ReleaseFileSource{Kind: "git", Repo: repo, Ref: q.Get("ref"), Path: p, Checksum: checksum}, nil
+	case strings.HasPrefix(src, "https://") || strings.HasPrefix(src, "http://"):
+		return &ReleaseFileSource{Kind: "http", Path: src, Checksum: checksum}, nil
+	default:
+		return &ReleaseFileSource{Kind: "file", Path: src}, nil
+	}
+}
+
+// cutSubPath splits <url>//<path> into url and path, skipping over the "//"
+// that follows the url scheme.
+func cutSubPath(s string) (string, string, bool) {
+	var scheme string
+	if idx := strings.Index(s, "://"); idx != -1 {
+		scheme, s = s[:idx+3], s[idx+3:]
+	}
+	u, p, ok := strings.Cut(s, "//")
+	return scheme + u, p, ok
+}
+
+// Pinned reports whether the source always resolves to the same content,
+// so that a cached copy can be reused.
+func (s ReleaseFileSource) Pinned() bool {
+	return s.Checksum != "" || (s.Kind == "git" && fullSHA.MatchString(s.Ref))
+}
+
+func (s ReleaseFileSource) String() string {
+	switch s.Kind {
+	case "git":
+		if s.Repo == "" {
+			return fmt.Sprintf("%s%s:%s", releaseFileGitPrefix, s.Ref, s.Path)
+		}
+		return fmt.Sprintf("%s%s//%s?ref=%s", releaseFileGitPrefix, s.Repo, s.Path, s.Ref)
+	case "pr":
+		return fmt.Sprintf("%s%s//%s", releaseFilePRPrefix, s.Ref, s.Path)
+	default:
+		return s.Path
+	}
+}
+
+func releaseFileCacheDir() string {
+	return filepath.Join(os.TempDir(), "release-automaton", "release-files")
+}
+
+func cachedReleaseFile(key, name string) string {
+	h := sha256.Sum256([]byte(key))
+	return filepath.Join(releaseFileCacheDir(), hex.EncodeToString(h[:8]), filepath.Base(name))
+}
+
+// releaseFileCacheKey identifies the cached copy of s. The checksum is part
+// of the key, so that changing it never picks up a copy verified against
+// another checksum.
+func releaseFileCacheKey(s *ReleaseFileSource) string {
+	if s.Checksum == "" {
+		return s.String()
+	}
+	return s.String() + "#" + s.Checksum
+}
+
+// cachedPinnedReleaseFile returns the cached copy of a pinned source. The
+// checksum of the copy is verified again, a mismatching copy is removed.
+func cachedPinnedReleaseFile(s *ReleaseFileSource, key string) (string, bool) {
+	filename := cachedReleaseFile(key, s.Path)
+	if !s.Pinned() || !Exists(filename) {
+		return "", false
+	}
+	if s.Checksum != "" {
+		data, err := os.ReadFile(filename)
+		if err == nil {
+			err = VerifyChecksum(data, s.Checksum)
+		}
+		if err != nil {
+			log.Printf("discarding cached release file %s for %s: %v", filename, s, err)
+			_ = os.Remove(filename)
+			return "", false
+		}
+	}
+	log.Printf("using cached release file %s for %s", filename, s)
+	return filename, true
+}
+
+// FetchReleaseFile returns the path to a local copy of the release file src.
+// Remote files are downloaded into a cache directory and their checksum is
+// verified, if provided.
+func FetchReleaseFile(sh *shell.Session, src string) (string, error) {
+	s, err := ParseReleaseFileSource(src)
+	if err != nil {
+		return "", err
+	}
+	if s.Kind == "file" {
+		return s.Path, nil
+	}
+
+	var data []byte
+	var key string
+	switch s.Kind {
+	case "http":
+		key = releaseFileCacheKey(s)
+		if filename, ok := cachedPinnedReleaseFile(s, key); ok {
+			return filename, nil
+		}
+		tmpDir, err := os.MkdirTemp("", "release-file")
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = os.RemoveAll(tmpDir) }()
+
+		tmpFile := filepath.Join(tmpDir, "release")
+		err = getter.GetFile(tmpFile, s.Path, getter.WithContext(context.TODO()))
+		if err != nil {
+			return "", err
+		}
+		data, err = os.ReadFile(tmpFile)
+		if err != nil {
+			return "", err
+		}
+	case "git":
+		key = releaseFileCacheKey(s)
+		if filename, ok := cachedPinnedReleaseFile(s, key); ok {
+			return filename, nil
+		}
+		data, err = gitShowFile(sh, s.Repo, s.Ref, s.Path)
+		if err != nil {
+			return "", err
+		}
+	case "pr":
+		var sha string
+		data, sha, err = prHeadFile(s.Ref, s.Path)
+		if err != nil {
+			return "", err
+		}
+		// the head commit pins the content
+		key = releaseFileCacheKey(s) + "@" + sha
+	}
+
+	if s.Checksum != "" {
+		err = VerifyChecksum(data, s.Checksum)
+		if err != nil {
+			return "", fmt.Errorf("release file %s: %w", s, err)
+		}
+	}
+
+	filename := cachedReleaseFile(key, s.Path)
+	err = os.MkdirAll(filepath.Dir(filename), 0o755)
+	if err != nil {
+		return "", err
+	}
+	err = os.WriteFile(filename, data, 0o644)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("fetched release file from %s into %s", key, filename)
+	return filename, nil
+}
+
+// maxReleaseBaseDepth limits how many base releases a release file can be
+// stacked upon, which also catches cycles.
+const maxReleaseBaseDepth = 10
+
+// LoadRelease reads the release file src, fetching it first if needed.
+// Release files that declare a base release are resolved into a full release.
+func LoadRelease(sh *shell.Session, src string) (api.Release, error) {
+	return loadRelease(sh, src, 0)
+}
+
+func loadRelease(sh *shell.Session, src string, depth int) (api.Release, error) {
+	var release api.Release
+
+	filename, err := FetchReleaseFile(sh, src)
+	if err != nil {
+		return release, err
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return release, err
+	}
+	err = yaml.Unmarshal(data, &release)
+	if err != nil || release.Base == "" {
+		return release, err
+	}
+
+	if depth >= maxReleaseBaseDepth {
+		return release, fmt.Errorf("release %s has too many levels of base releases", release.Release)
+	}
+	baseSrc, err := BaseReleaseFile(src, release.Base)
+	if err != nil {
+		return release, err
+	}
+	log.Printf("resolving release %s against base %s from %s", release.Release, release.Base, baseSrc)
+	base, err := loadRelease(sh, baseSrc, depth+1)
+	if err != nil {
+		return release, err
+	}
+	return release.Resolve(base)
+}
+
+// BaseReleaseFile returns the release file of the base release, found at
+// releases/<base>/release.json next to the release file src.
+func BaseReleaseFile(src, base string) (string, error) {
+	s, err := ParseReleaseFileSource(src)
+	if err != nil {
+		return "", err
+	}
+	s.Checksum = ""
+
+	switch s.Kind {
+	case "file":
+		filename := filepath.Join(filepath.Dir(filepath.Dir(s.Path)), base, filepath.Base(s.Path))
+		if !Exists(filename) {
+			filename = filepath.Join(api.ReleasesDir, base, "release.json")
+		}
+		return filename, nil
+	case "http":
+		u, err := url.Parse(s.Path)
+		if err != nil {
+			return "", err
+		}
+		u.Path = path.Join(path.Dir(path.Dir(u.Path)), base, path.Base(u.Path))
+		s.Path = u.String()
+	default:
+		s.Path = path.Join(path.Dir(path.Dir(s.Path)), base, path.Base(s.Path))
+	}
+	return s.String(), nil
+}
+
+func gitShowFile(sh *shell.Session, repo, ref, p string) ([]byte, error) {
+	if repo == "" {
+		// git show <ref>:<path> from the release tracker repo
+		return sh.Command("git", "show", fmt.Sprintf("%s:%s", ref, p)).Output()
+	}
+
+	wdOrig := sh.Getwd()
+	defer sh.SetDir(wdOrig)
+
+	h := sha256.Sum256([]byte(repo))
+	dir := filepath.Join(releaseFileCacheDir(), "repos", hex.EncodeToString(h[:8]))
+	if !Exists(filepath.Join(dir, ".git")) {
+		err := os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return nil, err
+		}
+		sh.SetDir(dir)
+		err = sh.Command("git", "init", "--quiet").Run()
+		if err != nil {
+			return nil, err
+		}
+		err = sh.Command("git", "remote", "add", "origin", repo).Run()
+		if err != nil {
+			return nil, err
+		}
+	}
+	sh.SetDir(dir)
+
+	// the cached repo may hold an expired token
+	if u, err := url.Parse(repo); err == nil && u.Hostname() == "github.com" {
+		err = ConfigureGitHubAuth(sh)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := FetchRepo(sh, "--quiet", "--depth=1", "origin", ref)
+	if err != nil {
+		return nil, err
+	}
+	return sh.Command("git", "show", "FETCH_HEAD:"+p).Output()
+}
+
+func prHeadFile(prURL, p string) ([]byte, string, error) {
+	owner, repo, number := ParsePullRequestURL(prURL)
+
+	gh, err := NewGitHubClient()
+	if err != nil {
+		return nil, "", err
+	}
+	pr, _, err := gh.PullRequests.Get(context.TODO(), owner, repo, number)
+	if err != nil {
+		return nil, "", err
+	}
+	head := pr.GetHead()
+	sha := head.GetSHA()
+	fc, _, _, err := gh.Repositories.GetContents(context.TODO(), head.GetRepo().GetOwner().GetLogin(), head.GetRepo().GetName(), p, &github.RepositoryContentGetOptions{
+		Ref: sha,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if fc == nil {
+		return nil, "", fmt.Errorf("%s is not a file in pr %s", p, prURL)
+	}
+	content, err := fc.GetContent()
+	if err != nil {
+		return nil, "", err
+	}
+	return []byte(content), sha, nil
+}
+
+// VerifyChecksum checks data against a checksum formatted as <type>:<hex>,
+// where type is one of md5, sha1, sha256 or sha512.
+func VerifyChecksum(data []byte, checksum string) error {
+	typ, expected, ok := strings.Cut(checksum, ":")
+	if !ok {
+		return fmt.Errorf("checksum %s must be formatted as <type>:<value>", checksum)
+	}
+
+	var h hash.Hash
+	switch typ {
+	case "md5":
+		h = md5.New()
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported checksum type %s", typ)
+	}
+	h.Write(data)
+	if actual := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch, expected %s got %s:%s", checksum, typ, actual)
+	}
+	return nil
+}
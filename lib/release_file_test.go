@@ -0,0 +1,116 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseReleaseFileSource(t *testing.T) {
+	tests := []struct {
+		src  string
+		want ReleaseFileSource
+	}{
+		{
+			src:  "releases/v2026.7.10/release.json",
+			want: ReleaseFileSource{Kind: "file", Path: "releases/v2026.7.10/release.json"},
+		},
+		{
+			src:  "https://example.com/release.json?checksum=sha256:abcd",
+			want: ReleaseFileSource{Kind: "http", Path: "https://example.com/release.json", Checksum: "sha256:abcd"},
+		},
+		{
+			src:  "git::a1b2c3:releases/v2026.7.10/release.json",
+			want: ReleaseFileSource{Kind: "git", Ref: "a1b2c3", Path: "releases/v2026.7.10/release.json"},
+		},
+		{
+			src: "git::https://github.com/kubedb/CHANGELOG.git//releases/v2026.7.10/release.json?ref=master&checksum=sha1:ff",
+			want: ReleaseFileSource{
+				Kind:     "git",
+				Repo:     "https://github.com/kubedb/CHANGELOG.git",
+				Ref:      "master",
+				Path:     "releases/v2026.7.10/release.json",
+				Checksum: "sha1:ff",
+			},
+		},
+		{
+			src:  "pr::https://github.com/kubedb/CHANGELOG/pull/123//releases/v2026.7.10/release.json",
+			want: ReleaseFileSource{Kind: "pr", Ref: "https://github.com/kubedb/CHANGELOG/pull/123", Path: "releases/v2026.7.10/release.json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			got, err := ParseReleaseFileSource(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("ParseReleaseFileSource() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("hello\n")
+	if err := VerifyChecksum(data, "sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"); err != nil {
+		t.Error(err)
+	}
+	if err := VerifyChecksum(data, "sha256:00"); err == nil {
+		t.Error("expected checksum mismatch")
+	}
+}
+
+func TestCachedPinnedReleaseFile(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	s := &ReleaseFileSource{
+		Kind:     "http",
+		Path:     "https://example.com/release.json",
+		Checksum: "sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+	}
+	key := releaseFileCacheKey(s)
+	filename := cachedReleaseFile(key, s.Path)
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filename, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := cachedPinnedReleaseFile(s, key); !ok || got != filename {
+		t.Errorf("expected cached copy %s, found %s", filename, got)
+	}
+
+	if err := os.WriteFile(filename, []byte("tampered\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cachedPinnedReleaseFile(s, key); ok {
+		t.Error("expected tampered copy to be rejected")
+	}
+	if Exists(filename) {
+		t.Error("expected tampered copy to be removed")
+	}
+
+	other := *s
+	other.Checksum = "sha256:00"
+	if releaseFileCacheKey(&other) == key {
+		t.Error("expected the checksum to be part of the cache key")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"github.com/google/go-github/v45/github"
+)
+
+const LabelActionRequired = "release-note-action-required"
+
+var (
+	// ref: https://github.com/kubernetes/community/blob/master/contributors/guide/release-notes.md
+	releaseNoteBlockRegex   = regexp.MustCompile("(?s)```release-note\\s*\\r?\\n(.*?)```")
+	releaseNoteTrailerRegex = regexp.MustCompile(`(?mi)^release-note:[ \t]*(.+)$`)
+	actionRequiredRegex     = regexp.MustCompile(`(?i)^action required:?\s*`)
+)
+
+// ParseReleaseNotes returns the release notes in ```release-note blocks of a
+// pull request description and the Release-note: trailers of a commit
+// message. Notes that say NONE are ignored.
+func ParseReleaseNotes(s string) []string {
+	var notes []string
+	add := func(note string) {
+		note = strings.TrimSpace(strings.ReplaceAll(note, "\r\n", "\n"))
+		if note == "" || strings.EqualFold(note, "none") || strings.EqualFold(note, "n/a") {
+			return
+		}
+		notes = append(notes, note)
+	}
+	for _, m := range releaseNoteBlockRegex.FindAllStringSubmatch(s, -1) {
+		add(m[1])
+	}
+	for _, m := range releaseNoteTrailerRegex.FindAllStringSubmatch(s, -1) {
+		add(m[1])
+	}
+	return notes
+}
+
+// SetReleaseNote sets the release note of c. Notes prefixed with
+// "ACTION REQUIRED:" mark the commit as action required.
+func SetReleaseNote(c *api.Commit, notes []string) {
+	for idx, note := range notes {
+		if actionRequiredRegex.MatchString(note) {
+			c.ActionRequired = true
+			notes[idx] = actionRequiredRegex.ReplaceAllString(note, "")
+		}
+	}
+	c.ReleaseNote = strings.Join(notes, "\n\n")
+}
+
+// CollectReleaseNotes sets the release notes and advisories of commits from
+// the descriptions of their pull requests. The pull requests fetched by
+// EnrichCommits and CategorizeCommits are reused. Errors are logged and
+// ignored.
+func CollectReleaseNotes(gh *github.Client, repoURL string, commits []api.Commit) []api.Commit {
+	for idx, c := range commits {
+		if c.PR == 0 {
+			continue
+		}
+		pr, err := GetPullRequest(gh, repoURL, c.PR)
+		if err != nil {
+			log.Printf("failed to get pull request %s#%d: %v", repoURL, c.PR, err)
+			continue
+		}
+		commits[idx].Advisories = append(commits[idx].Advisories, ParseAdvisories(pr.GetTitle()+"\n"+pr.GetBody())...)
+		notes := ParseReleaseNotes(pr.GetBody())
+		if len(notes) == 0 {
+			continue
+		}
+		// notes of the pull request replace the commit trailers, as squash
+		// merges copy the pull request description into the commit message
+		commits[idx].ActionRequired = false
+		SetReleaseNote(&commits[idx], notes)
+		for _, label := range pr.Labels {
+			if label.GetName() == LabelActionRequired {
+				commits[idx].ActionRequired = true
+			}
+		}
+	}
+	return commits
+}
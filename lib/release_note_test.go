@@ -0,0 +1,39 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+func TestParseReleaseNotes(t *testing.T) {
+	body := "This pr adds TLS.\r\n\r\n```release-note\r\nACTION REQUIRED: Set spec.tls.issuerRef\r\n```\n\n```release-note\nNONE\n```\n\nSigned-off-by: Jane\nRelease-note: Support TLS for Postgres\n"
+	notes := ParseReleaseNotes(body)
+	want := []string{"ACTION REQUIRED: Set spec.tls.issuerRef", "Support TLS for Postgres"}
+	if !reflect.DeepEqual(notes, want) {
+		t.Fatalf("ParseReleaseNotes() = %q, want %q", notes, want)
+	}
+
+	var c api.Commit
+	SetReleaseNote(&c, notes)
+	if !c.ActionRequired || c.ReleaseNote != "Set spec.tls.issuerRef\n\nSupport TLS for Postgres" {
+		t.Errorf("unexpected commit %+v", c)
+	}
+}
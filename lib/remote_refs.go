@@ -0,0 +1,99 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"strings"
+	"sync"
+
+	shell "gomodules.xyz/go-sh"
+)
+
+// remoteRefs caches the refs of remote repos for the current run, so that
+// all tag, branch and hash queries of a repo cost a single git ls-remote.
+var remoteRefs = struct {
+	sync.Mutex
+	refs map[string]map[string]string // repo url -> ref name -> sha
+}{refs: map[string]map[string]string{}}
+
+// ListRemoteRefs returns the branches and tags of remote, either origin of the
+// current repo or a repo url, keyed by full ref name, eg, refs/tags/v0.1.0.
+func ListRemoteRefs(sh *shell.Session, remote string) (map[string]string, error) {
+	key, err := remoteKey(sh, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteRefs.Lock()
+	defer remoteRefs.Unlock()
+
+	if refs, ok := remoteRefs.refs[key]; ok {
+		return refs, nil
+	}
+	if err := RefreshGitHubAuth(); err != nil {
+		return nil, err
+	}
+	// git ls-remote --heads --tags <remote>
+	data, err := sh.Command("git", "ls-remote", "--heads", "--tags", remote).Output()
+	if err != nil {
+		return nil, err
+	}
+	refs := ParseRemoteRefs(string(data))
+	remoteRefs.refs[key] = refs
+	return refs, nil
+}
+
+// InvalidateRemoteRefs drops the cached refs of remote after a push.
+func InvalidateRemoteRefs(sh *shell.Session, remote string) {
+	key, err := remoteKey(sh, remote)
+	if err != nil {
+		return
+	}
+
+	remoteRefs.Lock()
+	defer remoteRefs.Unlock()
+	delete(remoteRefs.refs, key)
+}
+
+// remoteKey returns the repo url of remote without scheme and .git suffix,
+// eg, github.com/kubedb/mongodb.
+func remoteKey(sh *shell.Session, remote string) (string, error) {
+	if !strings.Contains(remote, "/") {
+		// git remote get-url origin
+		data, err := sh.Command("git", "remote", "get-url", remote).Output()
+		if err != nil {
+			return "", err
+		}
+		remote = strings.TrimSpace(string(data))
+	}
+	if i := strings.Index(remote, "://"); i >= 0 {
+		remote = remote[i+len("://"):]
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(remote, "/"), ".git"), nil
+}
+
+// ParseRemoteRefs parses the output of git ls-remote.
+func ParseRemoteRefs(out string) map[string]string {
+	refs := map[string]string{}
+	for line := range strings.SplitSeq(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			refs[fields[1]] = fields[0]
+		}
+	}
+	return refs
+}
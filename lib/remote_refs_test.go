@@ -0,0 +1,80 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	shell "gomodules.xyz/go-sh"
+)
+
+func TestParseRemoteRefs(t *testing.T) {
+	out := `3f2a1c0d9e8b7a6f5e4d3c2b1a0f9e8d7c6b5a49	HEAD
+3f2a1c0d9e8b7a6f5e4d3c2b1a0f9e8d7c6b5a49	refs/heads/master
+9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b	refs/heads/release-v0.1
+1111111111111111111111111111111111111111	refs/tags/v0.1.0
+3f2a1c0d9e8b7a6f5e4d3c2b1a0f9e8d7c6b5a49	refs/tags/v0.1.0^{}
+`
+	want := map[string]string{
+		"HEAD":                    "3f2a1c0d9e8b7a6f5e4d3c2b1a0f9e8d7c6b5a49",
+		"refs/heads/master":       "3f2a1c0d9e8b7a6f5e4d3c2b1a0f9e8d7c6b5a49",
+		"refs/heads/release-v0.1": "9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b",
+		"refs/tags/v0.1.0":        "1111111111111111111111111111111111111111",
+		"refs/tags/v0.1.0^{}":     "3f2a1c0d9e8b7a6f5e4d3c2b1a0f9e8d7c6b5a49",
+	}
+	if got := ParseRemoteRefs(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRemoteRefs() = %v, want %v", got, want)
+	}
+}
+
+func TestRemoteRefsInvalidatedOnPush(t *testing.T) {
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	wd := filepath.Join(t.TempDir(), "repo")
+
+	sh := shell.NewSession()
+	sh.ShowCMD = false
+	sh.Stdout = io.Discard
+	sh.Stderr = io.Discard
+	for _, args := range [][]any{
+		{"init", "--quiet", "--bare", remote},
+		{"init", "--quiet", "-b", "master", wd},
+		{"-C", wd, "remote", "add", "origin", remote},
+		{"-C", wd, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "-m", "init"},
+		{"-C", wd, "tag", "v0.1.0"},
+	} {
+		if err := sh.Command("git", args...).Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sh.SetDir(wd)
+
+	if RemoteBranchExists(sh, "master") || RemoteTagExists(sh, "v0.1.0") {
+		t.Fatal("expected empty remote")
+	}
+	if err := PushRepo(sh, true); err != nil {
+		t.Fatal(err)
+	}
+	if !RemoteBranchExists(sh, "master") || !RemoteTagExists(sh, "v0.1.0") {
+		t.Error("expected refs of the pushed branch and tag")
+	}
+	if GetRemoteTag(sh, "v0.1.0") != LastCommitSHA(sh) {
+		t.Errorf("GetRemoteTag() = %s, want %s", GetRemoteTag(sh, "v0.1.0"), LastCommitSHA(sh))
+	}
+}
@@ -21,8 +21,31 @@ import (
 	"strings"
 
 	"github.com/appscodelabs/release-automaton/api"
+
+	"gomodules.xyz/sets"
+)
+
+var replyTypes = sets.NewString(
+	string(api.OkToRelease),
+	string(api.Done),
+	string(api.Tagged),
+	string(api.Go),
+	string(api.ReadyToTag),
+	string(api.CherryPicked),
+	string(api.PR),
+	string(api.PRClosed),
+	string(api.Chart),
+	string(api.ChartPublished),
+	string(api.KrewManifest),
+	string(api.KrewManifestPublished),
+	string(api.Note),
 )
 
+func isReply(s string) bool {
+	fields := strings.Fields(s)
+	return len(fields) > 0 && replyTypes.Has(fields[0])
+}
+
 func ParseReply(s string) *api.Reply {
 	fields := strings.Fields(s)
 	if len(fields) == 0 {
@@ -34,12 +57,20 @@ func ParseReply(s string) *api.Reply {
 
 	switch rt {
 	case api.OkToRelease:
-		fallthrough
-	case api.Done:
 		if len(params) > 0 {
 			panic(fmt.Errorf("unsupported parameters with reply %s", s))
 		}
 		return &api.Reply{Type: rt}
+	case api.Done:
+		if len(params) > 1 {
+			panic(fmt.Errorf("unsupported parameters with reply %s", s))
+		}
+		if len(params) == 1 {
+			return &api.Reply{Type: rt, Done: &api.DoneReplyData{
+				Product: params[0],
+			}}
+		}
+		return &api.Reply{Type: rt}
 	case api.Tagged:
 		if len(params) != 1 {
 			panic(fmt.Errorf("unsupported parameters with reply %s", s))
@@ -59,15 +90,19 @@ func ParseReply(s string) *api.Reply {
 			data.VCSRoot = params[2]
 		}
 		return &api.Reply{Type: rt, Go: data}
-	case api.PR:
+	case api.PR, api.PRClosed:
 		if len(params) != 1 {
 			panic(fmt.Errorf("unsupported parameters with reply %s", s))
 		}
 		owner, repo, prNumber := ParsePullRequestURL(params[0])
-		return &api.Reply{Type: rt, PR: &api.PullRequestReplyData{
+		data := &api.PullRequestReplyData{
 			Repo:   fmt.Sprintf("github.com/%s/%s", owner, repo),
 			Number: prNumber,
-		}}
+		}
+		if rt == api.PRClosed {
+			return &api.Reply{Type: rt, PRClosed: data}
+		}
+		return &api.Reply{Type: rt, PR: data}
 	case api.ReadyToTag:
 		if len(params) != 2 {
 			panic(fmt.Errorf("unsupported parameters with reply %s", s))
@@ -115,18 +150,51 @@ func ParseReply(s string) *api.Reply {
 		return &api.Reply{Type: rt, KrewManifestPublished: &api.KrewManifestPublishedReplyData{
 			Repo: params[0],
 		}}
+	case api.Note:
+		if len(params) == 0 {
+			panic(fmt.Errorf("missing repo with reply %s", s))
+		}
+		text := strings.TrimSpace(s)
+		text = strings.TrimSpace(strings.TrimPrefix(text, fields[0]))
+		text = strings.TrimSpace(strings.TrimPrefix(text, params[0]))
+		return &api.Reply{Type: rt, Note: &api.NoteReplyData{
+			Repo: params[0],
+			Text: text,
+		}}
 	default:
 		fmt.Printf("unknown reply type found in %s\n", s)
 		return nil
 	}
 }
 
+// ParseComment parses the replies in a comment, one per line. The text of a
+// /note continues until the next reply.
 func ParseComment(s string) []api.Reply {
 	var out []api.Reply
+	note := -1
 	for line := range strings.SplitSeq(s, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if note > -1 && !isReply(line) {
+			out[note].Note.Text += "\n" + line
+			continue
+		}
+		note = -1
 		if reply := ParseReply(line); reply != nil {
+			if reply.Type == api.Note {
+				note = len(out)
+			}
 			out = append(out, *reply)
 		}
 	}
-	return out
+	result := out[:0]
+	for _, reply := range out {
+		if reply.Type == api.Note {
+			reply.Note.Text = strings.TrimSpace(reply.Note.Text)
+			if reply.Note.Text == "" {
+				continue
+			}
+		}
+		result = append(result, reply)
+	}
+	return result
 }
@@ -0,0 +1,66 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+func TestParseCommentNotes(t *testing.T) {
+	comment := "/note github.com/kubedb/operator Requires CRD update.\r\n" +
+		"Run `kubectl apply -f crds/` before upgrading.\r\n" +
+		"/tagged github.com/kubedb/cli\r\n" +
+		"/note * Known issue: backups of MySQL 5.7 fail.\r\n" +
+		"/note github.com/kubedb/cli\r\n"
+
+	want := []api.Reply{
+		{Type: api.Note, Note: &api.NoteReplyData{
+			Repo: "github.com/kubedb/operator",
+			Text: "Requires CRD update.\nRun `kubectl apply -f crds/` before upgrading.",
+		}},
+		{Type: api.Tagged, Tagged: &api.TaggedReplyData{Repo: "github.com/kubedb/cli"}},
+		{Type: api.Note, Note: &api.NoteReplyData{
+			Repo: api.NoteAllRepos,
+			Text: "Known issue: backups of MySQL 5.7 fail.",
+		}},
+	}
+	if got := ParseComment(comment); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseComment() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseCommentPRClosed(t *testing.T) {
+	comment := "/pr https://github.com/kubedb/mongodb/pull/12\n" +
+		"/pr-closed https://github.com/kubedb/mongodb/pull/12\n"
+
+	pr := &api.PullRequestReplyData{Repo: "github.com/kubedb/mongodb", Number: 12}
+	want := []api.Reply{
+		{Type: api.PR, PR: pr},
+		{Type: api.PRClosed, PRClosed: pr},
+	}
+	got := ParseComment(comment)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseComment() = %+v, want %+v", got, want)
+	}
+	replies := api.MergeReplies(nil, got...)
+	if len(replies[api.PR]) != 1 || len(replies[api.PRClosed]) != 1 {
+		t.Errorf("unexpected replies %+v", replies)
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	"gomodules.xyz/sets"
+)
+
+const (
+	LabelSecurity = "security"
+	AdvisoriesDir = "advisories"
+)
+
+// ref: https://github.com/github/advisory-database#ghsa-ids
+var advisoryRegex = regexp.MustCompile(`(?i)\b(CVE-\d{4}-\d{4,}|GHSA(?:-[23456789cfghjmpqrvwx]{4}){3})\b`)
+
+// ParseAdvisories returns the CVE and GHSA ids mentioned in s in their
+// canonical form, eg, CVE-2026-1234 and GHSA-xxxx-xxxx-xxxx.
+func ParseAdvisories(s string) []string {
+	var out []string
+	for _, id := range advisoryRegex.FindAllString(s, -1) {
+		if strings.HasPrefix(strings.ToUpper(id), "CVE-") {
+			id = strings.ToUpper(id)
+		} else {
+			id = "GHSA" + strings.ToLower(id[4:])
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+// AdvisoryURL returns the url of a CVE or GHSA advisory.
+func AdvisoryURL(id string) string {
+	if strings.HasPrefix(id, "GHSA-") {
+		return "https://github.com/advisories/" + id
+	}
+	return "https://nvd.nist.gov/vuln/detail/" + id
+}
+
+// MarkSecurityFixes marks the commits labelled security or mentioning
+// advisories as security fixes.
+func MarkSecurityFixes(commits []api.Commit) []api.Commit {
+	for idx, c := range commits {
+		ids := sets.NewString(c.Advisories...)
+		ids.Insert(ParseAdvisories(c.Subject)...)
+		ids.Insert(ParseAdvisories(c.ReleaseNote)...)
+		commits[idx].Advisories = nil
+		if ids.Len() > 0 {
+			commits[idx].Advisories = ids.List()
+		}
+		commits[idx].Security = ids.Len() > 0 || sets.NewString(c.Labels...).Has(LabelSecurity)
+	}
+	return commits
+}
+
+// ref: https://ossf.github.io/osv-schema/
+type OSVAdvisory struct {
+	SchemaVersion    string            `json:"schema_version"`
+	ID               string            `json:"id"`
+	Modified         string            `json:"modified"`
+	Published        string            `json:"published"`
+	Aliases          []string          `json:"aliases,omitempty"`
+	Summary          string            `json:"summary"`
+	Details          string            `json:"details,omitempty"`
+	Affected         []OSVAffected     `json:"affected"`
+	References       []OSVReference    `json:"references,omitempty"`
+	DatabaseSpecific map[string]string `json:"database_specific,omitempty"`
+}
+
+type OSVAffected struct {
+	Ranges           []OSVRange        `json:"ranges"`
+	DatabaseSpecific map[string]string `json:"database_specific,omitempty"`
+}
+
+type OSVRange struct {
+	Type   string     `json:"type"`
+	Repo   string     `json:"repo"`
+	Events []OSVEvent `json:"events"`
+}
+
+type OSVEvent struct {
+	Introduced string `json:"introduced,omitempty"`
+	Fixed      string `json:"fixed,omitempty"`
+}
+
+type OSVReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// advisoryID returns the id of the advisory of a security fix in the
+// namespace of the product line, eg, KUBEDB-CVE-2026-1234. Fixes without
+// CVE or GHSA ids use the commit SHA instead.
+func advisoryID(productLine string, key string) string {
+	return strings.ToUpper(strings.ReplaceAll(productLine, " ", "")) + "-" + key
+}
+
+// OSVAdvisories returns one OSV advisory per CVE or GHSA id fixed in chlog,
+// listing the fixed tag of every affected project. Security fixes without
+// an id get an advisory of their own.
+func OSVAdvisories(chlog api.Changelog) []OSVAdvisory {
+	date := chlog.ReleaseDate.UTC().Format(time.RFC3339)
+	var keys []string
+	advisories := map[string]*OSVAdvisory{}
+	for _, fix := range chlog.SecurityFixes() {
+		c := fix.Commit
+		ids := c.Advisories
+		if len(ids) == 0 {
+			ids = []string{substr(c.SHA, 8)}
+		}
+		for _, key := range ids {
+			adv, ok := advisories[key]
+			if !ok {
+				adv = &OSVAdvisory{
+					SchemaVersion: "1.6.0",
+					ID:            advisoryID(chlog.ProductLine, key),
+					Modified:      date,
+					Published:     date,
+					Summary:       c.Subject,
+					Details:       c.ReleaseNote,
+					DatabaseSpecific: map[string]string{
+						"product_line": chlog.ProductLine,
+						"release":      chlog.Release,
+					},
+				}
+				if key != substr(c.SHA, 8) {
+					adv.Aliases = []string{key}
+					adv.References = append(adv.References, OSVReference{Type: "ADVISORY", URL: AdvisoryURL(key)})
+				}
+				advisories[key] = adv
+				keys = append(keys, key)
+			}
+			adv.Affected = append(adv.Affected, OSVAffected{
+				Ranges: []OSVRange{
+					{
+						Type: "GIT",
+						Repo: "https://" + fix.Repo,
+						Events: []OSVEvent{
+							{Introduced: "0"},
+							{Fixed: c.SHA},
+						},
+					},
+				},
+				DatabaseSpecific: map[string]string{
+					"fixed_version": fix.Tag,
+				},
+			})
+			adv.References = append(adv.References, OSVReference{Type: "FIX", URL: fmt.Sprintf("https://%s/commit/%s", fix.Repo, c.SHA)})
+			if c.PRURL != "" {
+				adv.References = append(adv.References, OSVReference{Type: "FIX", URL: c.PRURL})
+			}
+		}
+	}
+
+	out := make([]OSVAdvisory, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, *advisories[key])
+	}
+	return out
+}
+
+func substr(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// WriteAdvisories writes the OSV advisories of chlog to the advisories
+// directory of the release in dir. Advisories no longer in the changelog are
+// removed.
+func WriteAdvisories(dir string, chlog api.Changelog) error {
+	advDir := filepath.Join(dir, AdvisoriesDir)
+	existing, err := filepath.Glob(filepath.Join(advDir, "*.json"))
+	if err != nil {
+		return err
+	}
+	stale := sets.NewString(existing...)
+
+	for _, adv := range OSVAdvisories(chlog) {
+		err = os.MkdirAll(advDir, 0o755)
+		if err != nil {
+			return err
+		}
+		data, err := MarshalJson(adv)
+		if err != nil {
+			return err
+		}
+		filename := filepath.Join(advDir, adv.ID+".json")
+		err = os.WriteFile(filename, data, 0o644)
+		if err != nil {
+			return err
+		}
+		stale.Delete(filename)
+	}
+	for _, filename := range stale.UnsortedList() {
+		err = os.Remove(filename)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+func TestParseAdvisories(t *testing.T) {
+	got := ParseAdvisories("Fix cve-2026-12345 and GHSA-2C4M-59X9-FR2G (#12)\n\nSee CVE-26-1")
+	want := []string{"CVE-2026-12345", "GHSA-2c4m-59x9-fr2g"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAdvisories() = %v, want %v", got, want)
+	}
+}
+
+func TestOSVAdvisories(t *testing.T) {
+	fix := api.Commit{SHA: "0123456789abcdef", Subject: "Fix CVE-2026-1234 in parser"}
+	chlog := api.Changelog{
+		ProductLine: "KubeDB",
+		Release:     "v2026.7.10",
+		Projects: []api.ProjectChangelog{
+			{URL: "github.com/kubedb/operator", Releases: []api.ReleaseChangelog{{Tag: "v0.40.0", Commits: MarkSecurityFixes([]api.Commit{
+				fix,
+				{SHA: "fedcba9876543210", Subject: "Harden tls config", Labels: []string{LabelSecurity}},
+				{SHA: "1111111111111111", Subject: "Add backup"},
+			})}}},
+			{URL: "github.com/kubedb/cli", Releases: []api.ReleaseChangelog{{Tag: "v0.40.1", Commits: MarkSecurityFixes([]api.Commit{fix})}}},
+		},
+	}
+
+	advisories := OSVAdvisories(chlog)
+	if len(advisories) != 2 {
+		t.Fatalf("got %d advisories, want 2", len(advisories))
+	}
+	cve := advisories[0]
+	if cve.ID != "KUBEDB-CVE-2026-1234" || !reflect.DeepEqual(cve.Aliases, []string{"CVE-2026-1234"}) {
+		t.Errorf("unexpected advisory %s with aliases %v", cve.ID, cve.Aliases)
+	}
+	if len(cve.Affected) != 2 || cve.Affected[1].DatabaseSpecific["fixed_version"] != "v0.40.1" {
+		t.Errorf("unexpected affected projects %+v", cve.Affected)
+	}
+	if id := advisories[1].ID; id != "KUBEDB-fedcba98" {
+		t.Errorf("advisory of unidentified fix has id %s", id)
+	}
+}
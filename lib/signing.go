@@ -0,0 +1,153 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	shell "gomodules.xyz/go-sh"
+)
+
+// ConfigureSigning configures the git commands of sh to sign every commit and
+// tag with the key of cfg. The git config files of the user are left
+// untouched. It does nothing if cfg is nil. The returned func removes the
+// private key written to a temporary file, once the signing is done.
+func ConfigureSigning(sh *shell.Session, cfg *api.SigningConfig) (func(), error) {
+	if cfg == nil {
+		return func() {}, nil
+	}
+
+	keyFile, cleanup, err := signingKeyFile(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	signingKey := keyFile
+	if cfg.GetFormat() == api.SigningFormatOpenPGP {
+		// the key is read from the keyring after import
+		defer cleanup()
+		cleanup = func() {}
+
+		// gpg --batch --import <key>
+		err = sh.Command("gpg", "--batch", "--import", keyFile).Run()
+		if err != nil {
+			return nil, err
+		}
+		data, err := sh.Command("gpg", "--batch", "--with-colons", "--import-options", "show-only", "--import", keyFile).Output()
+		if err != nil {
+			return nil, err
+		}
+		signingKey = GPGFingerprint(string(data))
+		if signingKey == "" {
+			return nil, fmt.Errorf("no OpenPGP key found in the signing key")
+		}
+	}
+
+	for _, kv := range [][2]string{
+		{"gpg.format", string(cfg.GetFormat())},
+		{"user.signingkey", signingKey},
+		{"commit.gpgsign", "true"},
+		{"tag.gpgsign", "true"},
+	} {
+		err = SetSessionGitConfig(sh, kv[0], kv[1])
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+	}
+	return cleanup, nil
+}
+
+// signingKeyFile returns the path to the private key of cfg. A key passed
+// via environment is written to a temporary file readable only by the
+// current user, which is removed by the returned func.
+func signingKeyFile(cfg *api.SigningConfig) (string, func(), error) {
+	if key := os.Getenv(cfg.GetKeyEnv()); key != "" {
+		f, err := os.CreateTemp("", "signing-key-*")
+		if err != nil {
+			return "", nil, err
+		}
+		cleanup := func() { _ = os.Remove(f.Name()) }
+		if !strings.HasSuffix(key, "\n") {
+			key += "\n"
+		}
+		_, err = f.WriteString(key)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			cleanup()
+			return "", nil, err
+		}
+		return f.Name(), cleanup, nil
+	}
+	if cfg.KeyFile != "" {
+		p, err := filepath.Abs(cfg.KeyFile)
+		return p, func() {}, err
+	}
+	return "", nil, fmt.Errorf("missing signing key, set %s or key_file", cfg.GetKeyEnv())
+}
+
+// GPGFingerprint returns the fingerprint of the first key found in the
+// output of gpg --with-colons.
+func GPGFingerprint(out string) string {
+	var key bool
+	for line := range strings.SplitSeq(out, "\n") {
+		fields := strings.Split(line, ":")
+		switch fields[0] {
+		case "sec", "pub":
+			key = true
+		case "fpr":
+			if key && len(fields) > 9 {
+				return fields[9]
+			}
+		}
+	}
+	return ""
+}
+
+// ImportAllowedSigners imports the allowed OpenPGP keys of cfg into a new
+// keyring used by the later commands of sh. It returns the keyring dir.
+// SSH allowed signers are passed to git directly, see VerifyTag.
+func ImportAllowedSigners(sh *shell.Session, cfg *api.SigningConfig, allowedSignersFile string) (string, error) {
+	if cfg.GetFormat() != api.SigningFormatOpenPGP {
+		return "", nil
+	}
+	dir, err := os.MkdirTemp("", "gnupg-*")
+	if err != nil {
+		return "", err
+	}
+	sh.SetEnv("GNUPGHOME", dir)
+	return dir, sh.Command("gpg", "--batch", "--import", allowedSignersFile).Run()
+}
+
+// VerifyTag checks that tag in the current repo is signed by one of the
+// allowed signers.
+func VerifyTag(sh *shell.Session, cfg *api.SigningConfig, allowedSignersFile, tag string) error {
+	// git verify-tag <tag>
+	var args []any
+	if cfg.GetFormat() == api.SigningFormatSSH {
+		args = append(args, "-c", "gpg.ssh.allowedSignersFile="+allowedSignersFile)
+	}
+	args = append(args, "verify-tag", tag)
+	return sh.Command("git", args...).Run()
+}
@@ -0,0 +1,74 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	shell "gomodules.xyz/go-sh"
+	"gomodules.xyz/semvers"
+)
+
+// AncestorTags lists the tags reachable from the parent commits of tag, ie,
+// the tags on the branch tag was cut from. A tag on the first commit of a
+// repo has no ancestor tags.
+func AncestorTags(sh *shell.Session, tag string) ([]string, error) {
+	// git rev-list --parents -n 1 <tag>
+	data, err := sh.Command("git", "rev-list", "--parents", "-n", "1", tag).Output()
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.Fields(string(data))) < 2 {
+		return nil, nil
+	}
+
+	// git tag --merged <tag>^
+	data, err = sh.Command("git", "tag", "--merged", tag+"^").Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
+
+// PreviousTag picks the changelog base of vTag from its ancestor tags. It
+// returns the highest ancestor version lower than vTag that is at least as
+// important, eg, a release candidate is never the base of a stable release.
+// If sameLine is set, only tags with the same major and minor version are
+// considered. An empty string is returned if no tag qualifies.
+func PreviousTag(vTag *semver.Version, ancestors []string, sameLine bool) string {
+	var base *semver.Version
+	for _, x := range ancestors {
+		v, err := semver.NewVersion(x)
+		if err != nil {
+			continue
+		}
+		if !v.LessThan(vTag) || !semvers.AtLeastAsImp(vTag, v) {
+			continue
+		}
+		if sameLine && (v.Major() != vTag.Major() || v.Minor() != vTag.Minor()) {
+			continue
+		}
+		if base == nil || base.LessThan(v) {
+			base = v
+		}
+	}
+	if base == nil {
+		return ""
+	}
+	return base.Original()
+}
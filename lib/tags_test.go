@@ -0,0 +1,57 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestPreviousTag(t *testing.T) {
+	tests := []struct {
+		tag       string
+		ancestors []string
+		sameLine  bool
+		want      string
+	}{
+		{"v0.9.0", nil, false, ""},
+		{"v0.9.0", []string{"v0.8.0", "v0.8.1", "v0.9.0-rc.0", "latest"}, false, "v0.8.1"},
+		{"v0.9.0-rc.1", []string{"v0.8.1", "v0.9.0-rc.0"}, false, "v0.9.0-rc.0"},
+		// v0.10.0 was tagged on master after release-0.9 branched off
+		{"v0.9.4", []string{"v0.9.2", "v0.9.3", "v0.8.5"}, false, "v0.9.3"},
+		{"v0.9.4", []string{"v0.9.3", "v0.10.0", "v0.11.0"}, false, "v0.9.3"},
+		{"v0.9.0", []string{"v0.8.5", "v0.7.9"}, true, ""},
+		{"v0.9.4", []string{"v0.8.5", "v0.9.1", "v0.9.3"}, true, "v0.9.3"},
+	}
+	for _, tt := range tests {
+		got := PreviousTag(semver.MustParse(tt.tag), tt.ancestors, tt.sameLine)
+		if got != tt.want {
+			t.Errorf("PreviousTag(%q, %v, %v) = %q, want %q", tt.tag, tt.ancestors, tt.sameLine, got, tt.want)
+		}
+	}
+}
+
+func TestHasMessageLine(t *testing.T) {
+	msg := "v0.40.0\n\nProductLine: KubeDB\n\nRelease: v2026.7.10\n\nRelease-tracker: https://github.com/kubedb/CHANGELOG/pull/100\n"
+	if !HasMessageLine(msg, "Release-tracker: https://github.com/kubedb/CHANGELOG/pull/100") {
+		t.Error("expected release tracker line")
+	}
+	if HasMessageLine(msg, "Release-tracker: https://github.com/kubedb/CHANGELOG/pull/10") {
+		t.Error("unexpected match of another release tracker")
+	}
+}
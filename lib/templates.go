@@ -0,0 +1,200 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/appscodelabs/release-automaton/api"
+	"github.com/appscodelabs/release-automaton/templates"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/Masterminds/sprig/v3"
+	"gomodules.xyz/semvers"
+)
+
+var (
+	tplMu   sync.Mutex
+	tplDirs []string
+	tplSet  *template.Template
+)
+
+// SetTemplateDirs sets the directories whose *.tpl files overlay the
+// embedded templates. Later directories win. Missing directories are
+// ignored.
+func SetTemplateDirs(dirs ...string) {
+	tplMu.Lock()
+	defer tplMu.Unlock()
+
+	tplDirs = nil
+	for _, dir := range dirs {
+		if dir != "" && Exists(dir) {
+			tplDirs = append(tplDirs, dir)
+		}
+	}
+	tplSet = nil
+}
+
+type ProjectGroup struct {
+	Name     string
+	Projects []api.ProjectChangelog
+}
+
+// repoShortName returns the owner/repo part of a repo url.
+func repoShortName(repoURL string) string {
+	if _, rest, ok := strings.Cut(repoURL, "://"); ok {
+		repoURL = rest
+	}
+	repoURL = strings.TrimSuffix(repoURL, "/")
+	if _, path, ok := strings.Cut(repoURL, "/"); ok {
+		return path
+	}
+	return repoURL
+}
+
+// TemplateFuncs returns the functions available to templates, in addition to
+// the sprig functions.
+func TemplateFuncs() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	funcs["semverLess"] = func(a, b string) (bool, error) {
+		va, err := semver.NewVersion(a)
+		if err != nil {
+			return false, err
+		}
+		vb, err := semver.NewVersion(b)
+		if err != nil {
+			return false, err
+		}
+		return va.LessThan(vb), nil
+	}
+	funcs["semverSort"] = func(versions []string) []string {
+		return semvers.SortVersions(append([]string(nil), versions...), semvers.Compare)
+	}
+	funcs["repoShortName"] = repoShortName
+	funcs["repoName"] = func(repoURL string) string {
+		name := repoShortName(repoURL)
+		return name[strings.LastIndex(name, "/")+1:]
+	}
+	funcs["docsURL"] = func(urlTemplate, version string) string {
+		if strings.Contains(urlTemplate, "%s") {
+			return fmt.Sprintf(urlTemplate, version)
+		}
+		return strings.TrimSuffix(urlTemplate, "/") + "/" + version
+	}
+	funcs["advisoryURL"] = AdvisoryURL
+	funcs["groupByOwner"] = func(projects []api.ProjectChangelog) []ProjectGroup {
+		var out []ProjectGroup
+		idx := map[string]int{}
+		for _, p := range projects {
+			owner, _, _ := strings.Cut(repoShortName(p.URL), "/")
+			i, ok := idx[owner]
+			if !ok {
+				i = len(out)
+				idx[owner] = i
+				out = append(out, ProjectGroup{Name: owner})
+			}
+			out[i].Projects = append(out[i].Projects, p)
+		}
+		return out
+	}
+	funcs["groupByCategory"] = func(commits []api.Commit) []api.CommitSection {
+		return api.ReleaseChangelog{Commits: commits}.Sections()
+	}
+	return funcs
+}
+
+func loadTemplates() (*template.Template, error) {
+	tplMu.Lock()
+	defer tplMu.Unlock()
+
+	if tplSet != nil {
+		return tplSet, nil
+	}
+
+	files := map[string]string{}
+	var names []string
+	add := func(name, content string) {
+		if _, ok := files[name]; !ok {
+			names = append(names, name)
+		}
+		files[name] = content
+	}
+
+	err := iofs.WalkDir(templates.FS(), ".", func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := iofs.ReadFile(templates.FS(), path)
+		if err != nil {
+			return err
+		}
+		add(d.Name(), string(data))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range tplDirs {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.tpl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, filename := range matches {
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				return nil, err
+			}
+			add(filepath.Base(filename), string(data))
+		}
+	}
+
+	tpl := template.New("").Funcs(TemplateFuncs())
+	for _, name := range names {
+		tpl, err = tpl.New(name).Parse(files[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %v", name, err)
+		}
+	}
+	tplSet = tpl
+	return tplSet, nil
+}
+
+// RenderTemplate renders one of the embedded templates, or its overlay from
+// the template directories.
+func RenderTemplate(tplname string, data any) ([]byte, error) {
+	tpl, err := loadTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = tpl.ExecuteTemplate(&buf, tplname, data)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+func TestRenderTemplateOverlay(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "standalone-changelog.tpl"), []byte(`+++
+title = "{{ repoShortName "github.com/kubedb/operator" }} {{ docsURL "https://kubedb.com/docs/%s" .Release }}"
++++
+{{ range $g := groupByOwner .Projects }}{{ $g.Name }}:{{ range $g.Projects }} {{ repoName .URL }}{{ end }}
+{{ end }}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetTemplateDirs(filepath.Join(dir, "missing"), dir)
+	defer SetTemplateDirs()
+
+	chlog := api.Changelog{
+		Release: "v2026.7.10",
+		Projects: []api.ProjectChangelog{
+			{URL: "github.com/kubedb/cli"},
+			{URL: "github.com/kmodules/client-go"},
+			{URL: "github.com/kubedb/operator"},
+		},
+	}
+	data, err := RenderTemplate("standalone-changelog.tpl", chlog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `+++
+title = "kubedb/operator https://kubedb.com/docs/v2026.7.10"
++++
+kubedb: cli operator
+kmodules: client-go
+`
+	if string(data) != want {
+		t.Errorf("RenderTemplate() = %q, want %q", data, want)
+	}
+
+	// embedded templates are still available
+	if _, err := RenderTemplate("keep-a-changelog.tpl", KeepAChangelog{}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/appscodelabs/release-automaton/api"
+
+	shell "gomodules.xyz/go-sh"
+	"sigs.k8s.io/yaml"
+)
+
+// LoadTrain loads a release train file. Relative local release files of the
+// products are resolved against the directory of the train file.
+func LoadTrain(sh *shell.Session, src string) (api.ReleaseTrain, error) {
+	var train api.ReleaseTrain
+
+	filename, err := FetchReleaseFile(sh, src)
+	if err != nil {
+		return train, err
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return train, err
+	}
+	err = yaml.Unmarshal(data, &train)
+	if err != nil {
+		return train, err
+	}
+	if err = train.Validate(); err != nil {
+		return train, err
+	}
+
+	s, err := ParseReleaseFileSource(src)
+	if err != nil {
+		return train, err
+	}
+	if s.Kind == "file" {
+		for idx, p := range train.Products {
+			if ps, err := ParseReleaseFileSource(p.ReleaseFile); err == nil && ps.Kind == "file" && !filepath.IsAbs(p.ReleaseFile) {
+				train.Products[idx].ReleaseFile = filepath.Join(filepath.Dir(s.Path), p.ReleaseFile)
+			}
+		}
+	}
+	return train, nil
+}
+
+// TrainVar is a version published by a product of a release train.
+type TrainVar struct {
+	Value string
+	// Repo is set for project tags and empty for release level variables.
+	Repo string
+}
+
+// TrainVars returns the variables a product exposes to later products of a
+// train: RELEASE, PRODUCT_LINE and the *_TAG and *_VERSION variables of
+// projects with a single tag.
+func TrainVars(rel api.Release) map[string]TrainVar {
+	vars := map[string]TrainVar{
+		"RELEASE":      {Value: rel.Release},
+		"PRODUCT_LINE": {Value: rel.ProductLine},
+	}
+	for _, projects := range rel.Projects {
+		for repoURL, project := range projects {
+			if project.Tag == nil {
+				continue
+			}
+			vars[RepoURL2TagEnvKey(repoURL)] = TrainVar{Value: *project.Tag, Repo: repoURL}
+			if project.Key != "" {
+				vars[Key2EnvKey(project.Key)] = TrainVar{Value: *project.Tag, Repo: repoURL}
+			}
+		}
+	}
+	return vars
+}
+
+// TrainRef is a ${<product>.<VAR>} reference found in a release file.
+type TrainRef struct {
+	Product string
+	Var     string
+	TrainVar
+}
+
+// ResolveTrainRefs replaces the ${<product>.<VAR>} references in rel with
+// the values in vars (product -> TrainVars) and returns the references used.
+func ResolveTrainRefs(rel api.Release, vars map[string]map[string]TrainVar) (api.Release, []TrainRef, error) {
+	data, err := json.Marshal(rel)
+	if err != nil {
+		return rel, nil, err
+	}
+
+	used := map[string]TrainRef{}
+	var errs []error
+	data = api.TrainRefRegex.ReplaceAllFunc(data, func(m []byte) []byte {
+		groups := api.TrainRefRegex.FindSubmatch(m)
+		product, name := string(groups[1]), string(groups[2])
+		pv, ok := vars[product]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s references unknown or later product %s", m, product))
+			return m
+		}
+		v, ok := pv[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s references unknown variable %s of product %s", m, name, product))
+			return m
+		}
+		used[string(m)] = TrainRef{Product: product, Var: name, TrainVar: v}
+
+		escaped, _ := json.Marshal(v.Value)
+		return escaped[1 : len(escaped)-1]
+	})
+	if len(errs) > 0 {
+		return rel, nil, errs[0]
+	}
+
+	var out api.Release
+	if err = json.Unmarshal(data, &out); err != nil {
+		return rel, nil, err
+	}
+
+	refs := make([]TrainRef, 0, len(used))
+	for _, k := range Keys(used) {
+		refs = append(refs, used[k])
+	}
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].Product < refs[j].Product })
+	return out, refs, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright AppsCode Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"testing"
+
+	"github.com/appscodelabs/release-automaton/api"
+)
+
+func TestResolveTrainRefs(t *testing.T) {
+	kubedbCLI := "v0.66.0"
+	kubedb := api.Release{
+		ProductLine: "KubeDB",
+		Release:     "v2026.7.10",
+		Projects: []api.IndependentProjects{
+			{"github.com/kubedb/cli": api.Project{Tag: &kubedbCLI}},
+		},
+	}
+	vars := map[string]map[string]TrainVar{
+		"kubedb": TrainVars(kubedb),
+	}
+
+	installer := "v2026.7.12"
+	ace := api.Release{
+		ProductLine: "ACE",
+		Release:     "v2026.7.12",
+		Projects: []api.IndependentProjects{
+			{
+				"github.com/appscode-cloud/installer": api.Project{
+					Tag: &installer,
+					Commands: []string{
+						"./hack/scripts/update-kubedb.sh ${kubedb.RELEASE} ${kubedb.KUBEDB_CLI_TAG} ${TAG}",
+					},
+				},
+			},
+		},
+	}
+
+	got, refs, err := ResolveTrainRefs(ace, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "./hack/scripts/update-kubedb.sh v2026.7.10 v0.66.0 ${TAG}"
+	if cmd := got.Projects[0]["github.com/appscode-cloud/installer"].Commands[0]; cmd != want {
+		t.Errorf("command = %q, want %q", cmd, want)
+	}
+	if len(refs) != 2 {
+		t.Fatalf("expected 2 references, got %+v", refs)
+	}
+
+	ace.Projects[0]["github.com/appscode-cloud/installer"].Commands[0] = "echo ${kubestash.RELEASE}"
+	if _, _, err := ResolveTrainRefs(ace, vars); err == nil {
+		t.Error("expected error for unknown product")
+	}
+}
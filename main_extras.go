@@ -152,14 +152,16 @@ func main_UpdateChangelog() {
 	}
 
 	repoURL := "github.com/appscode-cloud/static-assets"
-	tag := "v1.0.0"
-	commits := []api.Commit{
-		{
-			SHA:     "DFGHJK45",
-			Subject: "This is a test",
+	rc := api.ReleaseChangelog{
+		Tag: "v1.0.0",
+		Commits: []api.Commit{
+			{
+				SHA:     "DFGHJK45",
+				Subject: "This is a test",
+			},
 		},
 	}
-	lib.UpdateChangelog(dir, release, repoURL, tag, commits)
+	lib.UpdateChangelog(dir, release, repoURL, rc)
 }
 
 func main_ParsePullRequestURL() {
@@ -0,0 +1,25 @@
+# {{ .ProductLine }} {{ .From }} to {{ .To }}
+
+Releases: {{ join ", " .Releases }}
+{{ with .BreakingProjects }}
+## Breaking Changes
+
+{{ range $p := . -}}
+- {{ trimPrefix "github.com/" $p.URL }} {{ $p.From | default "new" }} → {{ $p.To }}
+{{ end }}
+{{- end }}
+{{- range $p := .Projects }}
+## [{{ trimPrefix "github.com/" $p.URL }}](https://{{ $p.URL }})
+
+{{ if $p.From }}[{{ $p.From }}...{{ $p.To }}](https://{{ $p.URL }}/compare/{{ $p.From }}...{{ $p.To }}){{ else }}New in [{{ $p.To }}](https://{{ $p.URL }}/releases/tag/{{ $p.To }}){{ end }}{{ if $p.Breaking }} (breaking){{ end }}
+{{ range $s := $p.Sections }}
+{{- if $s.Category }}
+### {{ $s.Category }}
+{{ end }}
+{{ range $c := $s.Commits -}}
+ - [{{ substr 0 8 $c.SHA }}](https://{{ $p.URL }}/commit/{{ $c.SHA }}) {{ $c.Subject }}
+{{- if $c.PRURL }} ([#{{ $c.PR }}]({{ $c.PRURL }})){{ end }}
+{{- if $c.AuthorLogin }} by @{{ $c.AuthorLogin }}{{ else if $c.Author }} by {{ $c.Author }}{{ end }}
+{{ end }}
+{{- end }}
+{{ end }}
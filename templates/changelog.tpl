@@ -1,12 +1,97 @@
 # {{ .ProductLine }} {{ .Release }} ({{ .ReleaseDate | date "2006-01-02" }})
 
+{{ with .Notes -}}
+## Notes
+
+{{ range $n := . -}}
+- {{ replace "\n" "\n  " $n }}
+{{ end }}
+{{ end -}}
+{{ with .ActionRequired -}}
+## Action Required
+
+{{ range $n := . -}}
+{{ template "release-note" $n }}
+{{ end }}
+{{ end -}}
+{{ with .SecurityFixes -}}
+## Security Fixes
+
+{{ range $n := . -}}
+- {{ $n.Commit.Subject }}
+{{- range $id := $n.Commit.Advisories }} [{{ $id }}]({{ advisoryURL $id }}){{ end }}
+{{- " " }}([{{ trimPrefix "github.com/" $n.Repo }}@{{ $n.Tag }}]({{ if $n.Commit.PRURL }}{{ $n.Commit.PRURL }}{{ else }}https://{{ $n.Repo }}/commit/{{ $n.Commit.SHA }}{{ end }}))
+{{ end }}
+{{ end -}}
+{{ with .Highlights -}}
+## Highlights
+
+{{ range $n := . -}}
+{{ template "release-note" $n }}
+{{ end }}
+{{ end -}}
+{{ with .Collapsed -}}
+## Common Changes
+
+{{ range $c := . -}}
+- {{ $c.Subject }} ({{ range $i, $r := $c.Repos }}{{ if $i }}, {{ end }}[{{ trimPrefix "github.com/" $r }}](https://{{ $r }}){{ end }})
+{{ end }}
+{{ end -}}
 {{ range $p := .Projects }}
 ## [{{ trimPrefix "github.com/" $p.URL }}](https://{{ $p.URL }})
-{{ range $r := $p.Releases }}
+{{ with $p.Notes }}
+{{ range $n := . -}}
+- {{ replace "\n" "\n  " $n }}
+{{ end }}
+{{- end }}{{ range $r := $p.Releases }}
 ### [{{ $r.Tag }}](https://{{ $p.URL }}/releases/tag/{{ $r.Tag }})
-
-{{ range $c := $r.Commits -}}
+{{ with $r.APIChanges }}
+#### Incompatible API Changes
+{{ range $m := . }}
+`{{ $m.Module }}`
+{{ range $c := $m.Incompatible }}
+- {{ $c }}
+{{- end }}
+{{ end }}
+{{- end }}{{ range $s := $r.Sections }}
+{{- if $s.Category }}
+#### {{ $s.Category }}
+{{ end }}
+{{ range $c := $s.Commits -}}
  - [{{ substr 0 8 $c.SHA }}](https://{{ $p.URL }}/commit/{{ $c.SHA }}) {{ $c.Subject }}
+{{- if $c.PRURL }} ([#{{ $c.PR }}]({{ $c.PRURL }})){{ end }}
+{{- if $c.AuthorLogin }} by @{{ $c.AuthorLogin }}{{ else if $c.Author }} by {{ $c.Author }}{{ end }}
+{{ end }}
+{{- end }}
 {{ end }}
 {{ end }}
+
+{{- with .Contributors }}
+
+## Contributors
+
+Thanks to the {{ len . }} {{ if eq (len .) 1 }}contributor{{ else }}contributors{{ end }} of this release!
+{{ with $.FirstTimeContributors }}
+Welcome to our first-time contributors: {{ range $i, $ct := . }}{{ if $i }}, {{ end }}{{ template "contributor" $ct }}{{ end }}
 {{ end }}
+{{ range $ct := . -}}
+- {{ template "contributor" $ct }} ({{ $ct.Commits }} {{ if eq $ct.Commits 1 }}commit{{ else }}commits{{ end }})
+{{ end }}
+| Repository | Contributors | Commits |
+| ---------- | ------------ | ------- |
+{{ range $r := $.RepoContributors -}}
+| [{{ trimPrefix "github.com/" $r.Repo }}](https://{{ $r.Repo }}) | {{ $r.Contributors }} | {{ $r.Commits }} |
+{{ end }}
+{{ end -}}
+
+{{- define "release-note" -}}
+- {{ replace "\n" "\n  " .Commit.ReleaseNote }} ([{{ trimPrefix "github.com/" .Repo }}
+{{- if .Commit.PRURL }}#{{ .Commit.PR }}]({{ .Commit.PRURL }})
+{{- else }}@{{ substr 0 8 .Commit.SHA }}](https://{{ .Repo }}/commit/{{ .Commit.SHA }})
+{{- end }})
+{{- end }}
+
+
+{{- define "contributor" -}}
+{{ if .Login }}[@{{ .Name }}](https://github.com/{{ .Name }}){{ else }}{{ .Name }}{{ end }}
+{{- end }}
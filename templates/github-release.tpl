@@ -0,0 +1,17 @@
+Released as part of {{ .ProductLine }} [{{ .Release }}]({{ .ReleaseURL }})
+{{- with .Tracker }} ([release tracker]({{ . }})){{ end }}.
+{{ with .Changelog.APIChanges }}
+## Incompatible API Changes
+{{ range $m := . }}
+`{{ $m.Module }}`
+{{ range $c := $m.Incompatible }}
+- {{ $c }}
+{{- end }}
+{{ end }}
+{{- end }}{{ range $s := .Changelog.Sections }}
+## {{ if $s.Category }}{{ $s.Category }}{{ else }}Changes{{ end }}
+
+{{ range $c := $s.Commits -}}
+- {{ $c.Subject }}{{ if $c.PR }} (#{{ $c.PR }}){{ end }}{{ if $c.AuthorLogin }} by @{{ $c.AuthorLogin }}{{ end }} {{ substr 0 8 $c.SHA }}
+{{ end }}
+{{- end }}
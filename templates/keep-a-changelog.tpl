@@ -0,0 +1,16 @@
+# Changelog
+
+All notable changes to {{ .ProductLine }} are documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).
+Dependency updates and other changes are listed in the full changelog of each release.
+{{ range $r := .Releases }}
+## [{{ $r.Release }}]({{ $r.URL }}) - {{ $r.ReleaseDate | date "2006-01-02" }}
+{{ range $s := $r.Sections }}
+### {{ $s.Name }}
+
+{{ range $e := $s.Entries -}}
+- {{ $e }}
+{{ end }}
+{{- end }}
+{{- end }}